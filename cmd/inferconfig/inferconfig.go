@@ -1,42 +1,176 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
 	"github.com/CircleCI-Public/circleci-config/generation"
 	"github.com/CircleCI-Public/circleci-config/labeling"
 	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
-	"log"
-	"os"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
 )
 
 var stderr = log.New(os.Stderr, "", 0)
 
 func main() {
+	explain := flag.Bool("explain", false,
+		"print why each label was applied and which labels produced each job, instead of the config")
+	continuationConfig := flag.String("continuation-config", "",
+		"for codebases with several projects, print a setup config that only runs the jobs of the "+
+			"projects with changes, and write the config it continues with to this file")
+	revision := flag.String("revision", "",
+		"infer the config for this commit, branch or tag of the git repository at path, "+
+			"which can be bare, instead of for the files on disk")
+	flag.Usage = func() {
+		stderr.Printf("usage: %s [-explain] [-continuation-config file] [-revision rev] {path|url}", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	dir := "."
-	if len(os.Args) == 2 {
-		dir = os.Args[1]
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
 	}
 
-	stat, err := os.Stat(dir)
-	if len(os.Args) > 2 {
-		stderr.Printf("usage: %s {path}", os.Args[0])
+	if flag.NArg() > 1 {
+		flag.Usage()
 		os.Exit(1)
 	}
-	if os.IsNotExist(err) || !stat.IsDir() {
-		stderr.Printf("%s is not a directory", dir)
+
+	cb := openCodebase(dir, *revision)
+	// local dirs and git revisions list a limited number of files
+	if truncated, ok := cb.(interface{ Truncated() bool }); ok && truncated.Truncated() {
+		stderr.Printf("warning: %s has too many files, only some of them were scanned", dir)
+	}
+
+	if *explain {
+		fmt.Print(explainConfig(cb))
+		return
+	}
+
+	if *continuationConfig != "" {
+		setup, continuation, ok := inferDynamicConfig(cb)
+		if ok {
+			err := os.WriteFile(*continuationConfig, []byte(continuation), 0644)
+			if err != nil {
+				stderr.Printf("error writing to %s: %v", *continuationConfig, err)
+				os.Exit(3)
+			}
+			fmt.Print(setup)
+			return
+		}
+		stderr.Printf("less than two projects with jobs found in %s, printing a regular config", dir)
+	}
+
+	cfg := inferConfig(cb)
+	fmt.Print(cfg)
+}
+
+// usageError prints an error about the arguments and the usage, and exits
+func usageError(format string, args ...interface{}) {
+	stderr.Printf(format, args...)
+	flag.Usage()
+	os.Exit(1)
+}
+
+// openCodebase returns the codebase at dir, which can be a local directory, an archive, or
+// the URL of a server implementing the API of codebase.RemoteCodebase. It exits if there
+// is none.
+func openCodebase(dir string, revision string) codebase.Codebase {
+	if strings.HasPrefix(dir, "http://") || strings.HasPrefix(dir, "https://") {
+		if revision != "" {
+			usageError("-revision only applies to git repositories, %s is a URL", dir)
+		}
+		remote := codebase.NewRemoteCodebase(dir, nil)
+		// fetch the files rules read in batches, rather than with a request each
+		if err := remote.Prefetch(labeling.PrefetchFiles()...); err != nil {
+			stderr.Printf("warning: prefetching files from %s: %v", dir, err)
+		}
+		return remote
+	}
+
+	stat, err := os.Stat(dir)
+	isArchive := err == nil && !stat.IsDir() && codebase.IsArchive(dir)
+	if os.IsNotExist(err) || (err == nil && !stat.IsDir() && !isArchive) {
+		stderr.Printf("%s is not a directory or a .zip, .tar.gz or .tgz archive", dir)
 		os.Exit(2)
 	}
 	if err != nil {
-		stderr.Printf("error reading from %s: %v", os.Args, err)
+		stderr.Printf("error reading from %s: %v", dir, err)
 		os.Exit(3)
 	}
 
-	cfg := inferConfig(dir)
-	fmt.Print(cfg)
+	var cb codebase.Codebase = codebase.NewLocalCodebase(dir)
+	if isArchive {
+		if revision != "" {
+			usageError("-revision only applies to git repositories, %s is an archive", dir)
+		}
+		cb, err = codebase.OpenArchiveCodebase(dir)
+		if err != nil {
+			stderr.Printf("error reading archive %s: %v", dir, err)
+			os.Exit(3)
+		}
+	} else if revision != "" {
+		cb, err = codebase.OpenGitCodebase(dir, revision)
+		if err != nil {
+			stderr.Printf("error reading revision %s of %s: %v", revision, dir, err)
+			os.Exit(3)
+		}
+	}
+
+	return cb
+}
+
+// labelProjects labels the projects of cb, warning about the rules that failed, whose
+// labels are missing but don't prevent inferring a config from the other ones
+func labelProjects(cb codebase.Codebase) []labels.Project {
+	projects, err := labeling.ApplyAllRulesByProject(cb)
+	if err != nil {
+		stderr.Printf("warning: %v", err)
+	}
+	return projects
 }
 
-func inferConfig(dir string) string {
-	cb := codebase.LocalCodebase{BasePath: dir}
-	labels := labeling.ApplyAllRules(cb)
-	return generation.GenerateConfig(labels).String()
+func inferConfig(cb codebase.Codebase) string {
+	projects := labelProjects(cb)
+	return generation.GenerateProjectsConfig(projects).String()
+}
+
+// inferDynamicConfig returns a setup config and the continuation config it continues with,
+// see generation.GenerateDynamicConfig
+func inferDynamicConfig(cb codebase.Codebase) (setup string, continuation string, ok bool) {
+	projects := labelProjects(cb)
+	setupConfig, continuationConfig, ok := generation.GenerateDynamicConfig(projects)
+	return setupConfig.String(), continuationConfig.String(), ok
+}
+
+func explainConfig(cb codebase.Codebase) string {
+	projects := labelProjects(cb)
+
+	var sb strings.Builder
+	for _, p := range projects {
+		if len(projects) > 1 {
+			fmt.Fprintf(&sb, "Project %s:\n", p.BasePath)
+		}
+		keys := make([]string, 0, len(p.Labels))
+		for k := range p.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		sb.WriteString("Labels:\n")
+		for _, k := range keys {
+			fmt.Fprintf(&sb, "  %s (%s)\n", p.Labels[k], p.Labels[k].Evidence)
+		}
+	}
+
+	sb.WriteString("Jobs:\n")
+	for _, j := range generation.ExplainProjectJobs(projects) {
+		fmt.Fprintf(&sb, "  %s: %s\n", j.Job, strings.Join(j.Labels, ", "))
+	}
+	return sb.String()
 }
@@ -12,6 +12,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/CircleCI-Public/circleci-config/generation"
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase/remotetest"
+
 	"github.com/go-git/go-git/v5"
 )
 
@@ -63,7 +67,7 @@ func TestInferConfig(t *testing.T) {
 				return
 			}
 
-			got := inferConfig(dir)
+			got := inferConfig(codebase.NewLocalCodebase(dir))
 			expectedConfigFile := fmt.Sprintf("testdata/expected/%s.yml", path.Base(u.Path))
 			expectedBytes, err := os.ReadFile(expectedConfigFile)
 			if err != nil {
@@ -83,7 +87,7 @@ func TestInferConfig(t *testing.T) {
 }
 
 func TestDogfood(t *testing.T) {
-	got := inferConfig("../..")
+	got := inferConfig(codebase.NewLocalCodebase("../.."))
 	expectedBytes, err := os.ReadFile("testdata/expected/dogfood.yml")
 	if err != nil {
 		t.Error(err)
@@ -97,3 +101,75 @@ func TestDogfood(t *testing.T) {
 		fmt.Printf("diff: %s", d)
 	}
 }
+
+// TestInferConfig_javaJobs checks which of the java, android and kotlin multiplatform
+// jobs gradle builds get, without the demo repos of TestInferConfig
+func TestInferConfig_javaJobs(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		expectedJobs []string
+	}{
+		{
+			name: "android app in a subdir, like react native apps",
+			files: map[string]string{
+				"android/gradlew":          "",
+				"android/settings.gradle":  "include ':app'",
+				"android/build.gradle":     "buildscript {}",
+				"android/app/build.gradle": "apply plugin: \"com.android.application\"\n",
+			},
+			expectedJobs: []string{"test-android"},
+		},
+		{
+			name: "kotlin multiplatform with an android app",
+			files: map[string]string{
+				"gradlew":                     "",
+				"settings.gradle.kts":         "",
+				"shared/build.gradle.kts":     "plugins {\n  kotlin(\"multiplatform\")\n  id(\"com.android.library\")\n}\n",
+				"androidApp/build.gradle.kts": "plugins {\n  alias(libs.plugins.androidApplication)\n}\n",
+			},
+			expectedJobs: []string{"test-android", "test-kotlin-multiplatform"},
+		},
+		{
+			name: "java",
+			files: map[string]string{
+				"gradlew":      "",
+				"build.gradle": "plugins { id 'java' }\n",
+			},
+			expectedJobs: []string{"test-java"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, j := range generation.ExplainProjectJobs(labelProjects(codebase.MapCodebase(tt.files))) {
+				got = append(got, j.Job)
+			}
+			if d := cmp.Diff(tt.expectedJobs, got); d != "" {
+				t.Errorf("jobs mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestOpenCodebase_remote(t *testing.T) {
+	server := remotetest.NewServer(map[string]string{
+		"go.mod":              "module example.com/x\n\ngo 1.21\n",
+		"main.go":             "package main\n",
+		"web/package.json":    `{"scripts": {"test": "jest"}}`,
+		"web/src/index.js":    "",
+		"web/.prettierrc":     "{}",
+		"web/.eslintrc.json":  "{}",
+		"docker-compose.yaml": "services: {}\n",
+	})
+	defer server.Close()
+
+	_ = inferConfig(openCodebase(server.URL, ""))
+
+	// manifests and configs are fetched in a batch, and only main.go, which is read to
+	// find package main, is fetched on its own
+	expectedRequests := map[string]int{"tree": 1, "batch": 1, "raw": 1}
+	if d := cmp.Diff(expectedRequests, server.Requests()); d != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", d)
+	}
+}
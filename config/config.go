@@ -13,10 +13,14 @@ type Node interface {
 }
 
 type Config struct {
-	Comment   string
-	Workflows []*Workflow
-	Jobs      []*Job
-	Orbs      []Orb
+	Comment string
+	// Setup marks a setup config, which runs first to generate or pick the config to
+	// continue the pipeline with. See https://circleci.com/docs/dynamic-config/
+	Setup      bool
+	Parameters []Parameter
+	Workflows  []*Workflow
+	Jobs       []*Job
+	Orbs       []Orb
 }
 
 func (c Config) String() string {
@@ -26,6 +30,10 @@ func (c Config) String() string {
 func (c Config) YamlNode() *yaml.Node {
 	configNodes := []*yaml.Node{yScalar("version"), yScalar("2.1")}
 
+	if c.Setup {
+		configNodes = append(configNodes, yScalar("setup"), yScalar("true"))
+	}
+
 	orbsYaml := make([]*yaml.Node, 2*len(c.Orbs))
 	for i, o := range c.Orbs {
 		orbsYaml[2*i] = yScalar(o.Name)
@@ -36,10 +44,23 @@ func (c Config) YamlNode() *yaml.Node {
 		configNodes = append(configNodes, yScalar("orbs"), yMap(orbsYaml...))
 	}
 
-	jobsYaml := make([]*yaml.Node, 2*len(c.Jobs))
-	for i, j := range c.Jobs {
-		jobsYaml[2*i] = yScalar(j.Name)
-		jobsYaml[2*i+1] = j.YamlNode()
+	if len(c.Parameters) != 0 {
+		parametersYaml := make([]*yaml.Node, 2*len(c.Parameters))
+		for i, p := range c.Parameters {
+			parametersYaml[2*i] = yScalar(p.Name)
+			parametersYaml[2*i+1] = p.YamlNode()
+		}
+		configNodes = append(configNodes, yScalar("parameters"), yMap(parametersYaml...))
+	}
+
+	// a setup config can have only orb jobs
+	if len(c.Jobs) != 0 || !c.Setup {
+		jobsYaml := make([]*yaml.Node, 2*len(c.Jobs))
+		for i, j := range c.Jobs {
+			jobsYaml[2*i] = yScalar(j.Name)
+			jobsYaml[2*i+1] = j.YamlNode()
+		}
+		configNodes = append(configNodes, yScalar("jobs"), yMap(jobsYaml...))
 	}
 
 	workflowsYaml := make([]*yaml.Node, 2*len(c.Workflows))
@@ -48,15 +69,28 @@ func (c Config) YamlNode() *yaml.Node {
 		workflowsYaml[2*i+1] = w.YamlNode()
 	}
 
-	configNodes = append(configNodes,
-		yScalar("jobs"), yMap(jobsYaml...),
-		yScalar("workflows"), yMap(workflowsYaml...))
+	configNodes = append(configNodes, yScalar("workflows"), yMap(workflowsYaml...))
 
 	return yCommentedMap(c.Comment, configNodes...)
 }
 
+// Parameter is a pipeline parameter, see https://circleci.com/docs/pipeline-variables/
+type Parameter struct {
+	Name    string
+	Type    string // boolean, string, integer or enum
+	Default string
+}
+
+func (p Parameter) YamlNode() *yaml.Node {
+	return yMap(
+		yScalar("type"), yScalar(p.Type),
+		yScalar("default"), yScalar(p.Default))
+}
+
 type Workflow struct {
 	Name string
+	// When is a condition for running the workflow, like "<< pipeline.parameters.x >>"
+	When string
 	Jobs []WorkflowJob
 }
 
@@ -89,11 +123,16 @@ func (w Workflow) YamlNode() *yaml.Node {
 		jobsFootComment = yamlNodeToString(ySeq(commentedOutJobs...))
 	}
 
-	return yMap(&yaml.Node{
+	var whenYaml []*yaml.Node
+	if w.When != "" {
+		whenYaml = []*yaml.Node{yScalar("when"), yScalar(w.When)}
+	}
+
+	return yMap(append(whenYaml, &yaml.Node{
 		Kind:        yaml.ScalarNode,
 		Value:       "jobs",
 		FootComment: jobsFootComment,
-	}, ySeq(workflowJobsYaml...))
+	}, ySeq(workflowJobsYaml...))...)
 }
 
 type Orb struct {
@@ -111,6 +150,10 @@ type WorkflowJob struct {
 	Job          *Job
 	Requires     []*Job
 	CommentedOut bool
+	// Parameters for jobs that take them, like orb-defined jobs
+	Parameters OrbCommandParameters
+	// Contexts whose environment variables the job gets, like registry credentials
+	Contexts []string
 }
 
 func (wj WorkflowJob) String() string {
@@ -119,24 +162,41 @@ func (wj WorkflowJob) String() string {
 
 func (wj WorkflowJob) YamlNode() *yaml.Node {
 	nameYaml := yScalar(wj.Job.Name)
-	if wj.Requires == nil || len(wj.Requires) == 0 {
+	if len(wj.Requires) == 0 && len(wj.Parameters) == 0 && len(wj.Contexts) == 0 {
 		return nameYaml
 	}
-	requiresYaml := make([]*yaml.Node, len(wj.Requires))
-	for i, r := range wj.Requires {
-		requiresYaml[i] = yScalar(r.Name)
+
+	var kvs []*yaml.Node
+	if len(wj.Requires) != 0 {
+		requiresYaml := make([]*yaml.Node, len(wj.Requires))
+		for i, r := range wj.Requires {
+			requiresYaml[i] = yScalar(r.Name)
+		}
+		kvs = append(kvs, yScalar("requires"), ySeq(requiresYaml...))
+	}
+	if len(wj.Contexts) != 0 {
+		contextsYaml := make([]*yaml.Node, len(wj.Contexts))
+		for i, c := range wj.Contexts {
+			contextsYaml[i] = yScalar(c)
+		}
+		kvs = append(kvs, yScalar("context"), ySeq(contextsYaml...))
+	}
+	if len(wj.Parameters) != 0 {
+		kvs = append(kvs, yMapFromStringsMap(wj.Parameters).Content...)
 	}
-	return yMap(nameYaml, yMap(
-		yScalar("requires"), ySeq(requiresYaml...)))
+	return yMap(nameYaml, yMap(kvs...))
 }
 
 // Job definitions as they appear under config top-level "jobs:" key
 type Job struct {
 	Name    string
 	Comment string
-	// The following two fields are mutually exclusive
-	DockerImages     []string
-	Executor         string
+	// The following three fields are mutually exclusive
+	DockerImages []string
+	Executor     string
+	MacOSXcode   string // Xcode version of a macOS executor, like "15.1.0"
+
+	ResourceClass    string
 	WorkingDirectory string
 	Steps            []Step
 	Environment      map[string]string
@@ -152,6 +212,8 @@ func (j Job) YamlNode() *yaml.Node {
 
 	if j.Executor != "" {
 		contentNodes = append(contentNodes, yScalar("executor"), yScalar(j.Executor))
+	} else if j.MacOSXcode != "" {
+		contentNodes = append(contentNodes, yScalar("macos"), yMap(yScalar("xcode"), yScalar(j.MacOSXcode)))
 	} else {
 		imageNodes := make([]*yaml.Node, 0, len(j.DockerImages))
 		for _, img := range j.DockerImages {
@@ -160,6 +222,10 @@ func (j Job) YamlNode() *yaml.Node {
 		contentNodes = append(contentNodes, yScalar("docker"), ySeq(imageNodes...))
 	}
 
+	if j.ResourceClass != "" {
+		contentNodes = append(contentNodes, yScalar("resource_class"), yScalar(j.ResourceClass))
+	}
+
 	if j.WorkingDirectory != "" && j.WorkingDirectory != "." {
 		contentNodes = append(contentNodes, yScalar("working_directory"), yScalar(j.WorkingDirectory))
 	}
@@ -184,6 +250,7 @@ const (
 	StoreArtifacts
 	StoreTestResults
 	OrbCommand
+	SetupRemoteDocker
 )
 
 type WhenType uint32
@@ -224,6 +291,8 @@ type Step struct {
 	Destination string               // artifact destination
 	Parameters  OrbCommandParameters // for orb-defined steps
 	When        WhenType
+	// for setup_remote_docker steps, reuses the image layers of previous jobs
+	DockerLayerCaching bool
 }
 
 func (s Step) YamlNode() *yaml.Node {
@@ -285,6 +354,13 @@ func (s Step) YamlNode() *yaml.Node {
 		return yCommentedMap(s.Comment,
 			yScalar(s.Command),
 			yMapFromStringsMap(s.Parameters))
+	case SetupRemoteDocker:
+		if !s.DockerLayerCaching {
+			return yCommentedScalar(s.Comment, "setup_remote_docker")
+		}
+		return yCommentedMap(s.Comment,
+			yScalar("setup_remote_docker"),
+			yMap(yScalar("docker_layer_caching"), yScalar("true")))
 	}
 	panic("unknown step type")
 }
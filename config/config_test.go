@@ -104,6 +104,34 @@ func TestConfig_String(t *testing.T) {
 				"          requires:\n" +
 				"            - node-test-job\n",
 		},
+		{
+			testName: "setup config with parameters",
+			config: Config{
+				Setup:      true,
+				Parameters: []Parameter{{Name: "run-x", Type: "boolean", Default: "false"}},
+				Orbs:       []Orb{{Name: "path-filtering", RegistryKey: "circleci/path-filtering@1"}},
+				Workflows: []*Workflow{{
+					Name: "setup",
+					Jobs: []WorkflowJob{{
+						Job:        &Job{Name: "path-filtering/filter"},
+						Parameters: OrbCommandParameters{"mapping": "x/.* run-x true"},
+					}},
+				}},
+			},
+			expected: "version: 2.1\n" +
+				"setup: true\n" +
+				"orbs:\n" +
+				"  path-filtering: circleci/path-filtering@1\n" +
+				"parameters:\n" +
+				"  run-x:\n" +
+				"    type: boolean\n" +
+				"    default: false\n" +
+				"workflows:\n" +
+				"  setup:\n" +
+				"    jobs:\n" +
+				"      - path-filtering/filter:\n" +
+				"          mapping: x/.* run-x true\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.testName, func(t *testing.T) {
@@ -170,6 +198,52 @@ func TestWorkflow_YamlNode(t *testing.T) {
   - job3:
       requires:
         - job2
+`,
+		}, {
+			testName: "when condition and job parameters",
+			workflow: Workflow{
+				Name: "w",
+				When: "<< pipeline.parameters.run-w >>",
+				Jobs: []WorkflowJob{
+					{
+						Job: &job1,
+					}, {
+						Job:        &job2,
+						Requires:   []*Job{&job1},
+						Parameters: OrbCommandParameters{"b": "2", "a": "1"},
+					},
+				},
+			},
+			expected: `when: << pipeline.parameters.run-w >>
+jobs:
+  - job1
+  - job2:
+      requires:
+        - job1
+      a: 1
+      b: 2
+`,
+		}, {
+			testName: "job with contexts",
+			workflow: Workflow{
+				Name: "w",
+				Jobs: []WorkflowJob{
+					{
+						Job: &job1,
+					}, {
+						Job:      &job2,
+						Requires: []*Job{&job1},
+						Contexts: []string{"docker-registry"},
+					},
+				},
+			},
+			expected: `jobs:
+  - job1
+  - job2:
+      requires:
+        - job1
+      context:
+        - docker-registry
 `,
 		}, {
 			testName: "3 jobs fan-out",
@@ -428,6 +502,15 @@ steps:
       command: npm install
 `,
 		},
+		{
+			testName: "job with macos executor and resource class",
+			job: Job{
+				Name:          "job",
+				MacOSXcode:    "15.1.0",
+				ResourceClass: "macos.m1.medium.gen1",
+			},
+			expected: "macos:\n  xcode: 15.1.0\nresource_class: macos.m1.medium.gen1\nsteps: []\n",
+		},
 		{
 			testName: "job with executor and working dir",
 			job: Job{
@@ -539,6 +622,19 @@ func TestStep_YamlNode(t *testing.T) {
 				Parameters: OrbCommandParameters{"x": "1", "y": "2"},
 			},
 			expected: "orb/do_something:\n  x: 1\n  y: 2\n",
+		}, {
+			testName: "setup_remote_docker",
+			step: Step{
+				Type: SetupRemoteDocker,
+			},
+			expected: "setup_remote_docker\n",
+		}, {
+			testName: "setup_remote_docker with layer caching",
+			step: Step{
+				Type:               SetupRemoteDocker,
+				DockerLayerCaching: true,
+			},
+			expected: "setup_remote_docker:\n  docker_layer_caching: true\n",
 		},
 	}
 	for _, tt := range tests {
@@ -1,19 +1,122 @@
 package generation
 
 import (
+	"fmt"
+
 	"github.com/CircleCI-Public/circleci-config/config"
 	"github.com/CircleCI-Public/circleci-config/generation/internal"
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
 )
 
-func GenerateConfig(labels labels.LabelSet) config.Config {
+func GenerateConfig(ls labels.LabelSet) config.Config {
+	jobs := generateJobs(ls)
+	internal.AddSubmoduleSteps(ls[labels.VCSGitSubmodules], jobs)
+	return internal.BuildConfig(ls, jobs)
+}
+
+// GenerateProjectsConfig generates a config with the jobs of every project. If several
+// projects generate a job with the same name, like test-node, the project name is added
+// to it, like test-node-frontend and test-node-admin.
+func GenerateProjectsConfig(projects []labels.Project) config.Config {
+	return internal.BuildProjectsConfig(projects, generateProjectJobs(projects))
+}
+
+// ContinuationConfigPath is where the setup config generated by GenerateDynamicConfig
+// expects the continuation config to be in the repo
+const ContinuationConfigPath = internal.ContinuationConfigPath
+
+// GenerateDynamicConfig generates, for a codebase with several projects, a setup config
+// and a continuation config where the jobs of each project only run when files under its
+// BasePath change. The continuation config should be committed at ContinuationConfigPath.
+// It returns ok = false when less than two projects have jobs, in which case a
+// GenerateProjectsConfig config is better suited.
+func GenerateDynamicConfig(projects []labels.Project) (setup config.Config, continuation config.Config, ok bool) {
+	jobs := generateProjectJobs(projects)
+
+	projectsWithJobs := make(map[string]bool)
+	for _, j := range jobs {
+		projectsWithJobs[j.Project] = true
+	}
+	if len(projectsWithJobs) < 2 {
+		return config.Config{}, config.Config{}, false
+	}
+
+	setup, continuation = internal.BuildDynamicConfig(projects, jobs)
+	return setup, continuation, true
+}
+
+// JobExplanation lists the labels that caused a job to be generated
+type JobExplanation struct {
+	Job    string
+	Labels []string
+}
+
+// ExplainJobs returns, for each job GenerateConfig generates from labels, the keys of
+// the labels that produced it. Placeholder jobs added to complete the workflow are not
+// included, as no label produces them.
+func ExplainJobs(labels labels.LabelSet) []JobExplanation {
+	return explainJobs(generateJobs(labels))
+}
+
+// ExplainProjectJobs is like ExplainJobs, for the jobs GenerateProjectsConfig generates
+func ExplainProjectJobs(projects []labels.Project) []JobExplanation {
+	return explainJobs(generateProjectJobs(projects))
+}
+
+func explainJobs(jobs []*internal.Job) []JobExplanation {
+	explanations := make([]JobExplanation, len(jobs))
+	for i, j := range jobs {
+		explanations[i] = JobExplanation{Job: j.Name, Labels: j.Labels}
+	}
+	return explanations
+}
+
+func generateJobs(labels labels.LabelSet) []*internal.Job {
 	var generatedJobs []*internal.Job
 	generatedJobs = append(generatedJobs, internal.GenerateNodeJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateDenoJobs(labels)...)
 	generatedJobs = append(generatedJobs, internal.GenerateGoJobs(labels)...)
 	generatedJobs = append(generatedJobs, internal.GenerateJavaJobs(labels)...)
 	generatedJobs = append(generatedJobs, internal.GeneratePythonJobs(labels)...)
 	generatedJobs = append(generatedJobs, internal.GenerateRubyJobs(labels)...)
 	generatedJobs = append(generatedJobs, internal.GenerateRustJobs(labels)...)
 	generatedJobs = append(generatedJobs, internal.GeneratePHPJobs(labels)...)
-	return internal.BuildConfig(labels, generatedJobs)
+	generatedJobs = append(generatedJobs, internal.GenerateDotnetJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateElixirJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateSwiftJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateAndroidJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateScalaJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateClojureJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateCppJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateBazelJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateLintJobs(labels)...)
+	generatedJobs = append(generatedJobs, internal.GenerateDockerJobs(labels)...)
+	return generatedJobs
+}
+
+func generateProjectJobs(projects []labels.Project) []*internal.Job {
+	var jobs []*internal.Job
+	projectsByJobName := make(map[string]int)
+	for _, p := range projects {
+		for _, j := range generateJobs(p.Labels) {
+			j.Project = p.BasePath
+			projectsByJobName[j.Name]++
+			jobs = append(jobs, j)
+		}
+	}
+
+	for _, j := range jobs {
+		if projectsByJobName[j.Name] > 1 {
+			j.Name = fmt.Sprintf("%s-%s", j.Name, labels.Project{BasePath: j.Project}.Name())
+		}
+	}
+
+	// submodules are checked out for the jobs of every project, but only the root project
+	// has .gitmodules
+	for _, p := range projects {
+		if p.BasePath == "." {
+			internal.AddSubmoduleSteps(p.Labels[labels.VCSGitSubmodules], jobs)
+		}
+	}
+	return jobs
 }
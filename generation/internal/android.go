@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+const androidDockerImage = "cimg/android:2024.01"
+
+// collectGradleTestResultsStep copies the test results of every module to one dir, for
+// store_test_results
+var collectGradleTestResultsStep = config.Step{
+	Type:    config.Run,
+	Name:    "Collect test results",
+	Command: `mkdir -p ~/test-results/junit && find . -type f -regex ".*/build/test-results/.*xml" -exec cp {} ~/test-results/junit/ \;`,
+	When:    config.WhenTypeAlways,
+}
+
+func gradleTestSteps(depsLabel labels.Label, name string, command string) []config.Step {
+	return []config.Step{
+		checkoutStep(depsLabel),
+		{
+			Type:    config.Run,
+			Name:    "Calculate cache key",
+			Command: javaCacheKeyCommand,
+		},
+		{
+			Type:     config.RestoreCache,
+			CacheKey: javaCacheKey,
+		},
+		{
+			Type:    config.Run,
+			Name:    name,
+			Command: command,
+		},
+		collectGradleTestResultsStep,
+		{
+			Type: config.StoreTestResults,
+			Path: "~/test-results",
+		},
+		{
+			Type:     config.SaveCache,
+			CacheKey: javaCacheKey,
+			Path:     "~/.gradle/caches",
+		},
+	}
+}
+
+func androidTestJob(ls labels.LabelSet) *Job {
+	depsLabel := ls[labels.DepsAndroid]
+	return &Job{
+		Job: config.Job{
+			Name:             "test-android",
+			Comment:          "Run unit tests and lint",
+			DockerImages:     []string{androidDockerImage},
+			WorkingDirectory: workingDirectory(depsLabel),
+			Steps:            gradleTestSteps(depsLabel, "Run unit tests and lint", "./gradlew testDebugUnitTest lint"),
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsAndroid, labels.DepsJava),
+	}
+}
+
+// kotlinMultiplatformTestJob runs the tests of every target on macOS, which the iOS
+// targets need
+func kotlinMultiplatformTestJob(ls labels.LabelSet) *Job {
+	depsLabel := ls[labels.DepsKotlinMultiplatform]
+	return &Job{
+		Job: config.Job{
+			Name:             "test-kotlin-multiplatform",
+			Comment:          "Run the tests of all targets",
+			MacOSXcode:       xcodeFallbackVersion,
+			ResourceClass:    macOSResourceClass,
+			WorkingDirectory: workingDirectory(depsLabel),
+			Steps:            gradleTestSteps(depsLabel, "Run tests", "./gradlew allTests"),
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsKotlinMultiplatform, labels.DepsJava),
+	}
+}
+
+func GenerateAndroidJobs(ls labels.LabelSet) (jobs []*Job) {
+	if ls[labels.DepsAndroid].Valid {
+		jobs = append(jobs, androidTestJob(ls))
+	}
+	if ls[labels.DepsKotlinMultiplatform].Valid {
+		jobs = append(jobs, kotlinMultiplatformTestJob(ls))
+	}
+	return jobs
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// bazelisk downloads the Bazel version in .bazelversion, or the latest one
+const bazeliskVersion = "1.19.0"
+
+const bazelDiskCachePath = "~/.cache/bazel-disk"
+
+const bazelCacheKeyCommand = `find . -maxdepth 1 -name 'MODULE.bazel*' -o -name 'WORKSPACE*' -o -name .bazelversion | \
+        sort | xargs cat > /tmp/CIRCLECI_CACHE_KEY`
+
+// the disk cache is saved for every commit, as it changes with the sources and not only
+// with the workspace files, and restored from the latest one with the same workspace files
+const bazelRestoreCacheKey = `bazel-{{ checksum "/tmp/CIRCLECI_CACHE_KEY" }}-`
+const bazelSaveCacheKey = bazelRestoreCacheKey + `{{ .Revision }}`
+
+func bazelTestJob(ls labels.LabelSet) *Job {
+	label := ls[labels.BuildBazel]
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-bazel",
+			Comment:          "Build and run all tests with Bazel",
+			DockerImages:     []string{cppDockerImage},
+			WorkingDirectory: workingDirectory(label),
+			Steps: []config.Step{
+				checkoutStep(label),
+				{
+					Type: config.Run,
+					Name: "Install Bazel",
+					Command: fmt.Sprintf("sudo curl -fsSL -o /usr/local/bin/bazel "+
+						"https://github.com/bazelbuild/bazelisk/releases/download/v%s/bazelisk-linux-amd64 && "+
+						"sudo chmod +x /usr/local/bin/bazel", bazeliskVersion),
+				},
+				{
+					Type:    config.Run,
+					Name:    "Calculate cache key",
+					Command: bazelCacheKeyCommand,
+				},
+				{
+					Type:     config.RestoreCache,
+					CacheKey: bazelRestoreCacheKey,
+				},
+				{
+					Type:    config.Run,
+					Name:    "Run tests",
+					Command: "bazel test //... --disk_cache=" + bazelDiskCachePath + " --test_output=errors",
+				},
+				{
+					// every test target writes its own test.xml, so the dirs are kept
+					Type:    config.Run,
+					Name:    "Collect test results",
+					Command: `mkdir -p ~/test-results && find -L bazel-testlogs -name test.xml -exec cp --parents {} ~/test-results \;`,
+					When:    config.WhenTypeAlways,
+				},
+				{
+					Type: config.StoreTestResults,
+					Path: "~/test-results",
+				},
+				{
+					Type:     config.SaveCache,
+					CacheKey: bazelSaveCacheKey,
+					Path:     bazelDiskCachePath,
+				},
+			},
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.BuildBazel),
+	}
+}
+
+func GenerateBazelJobs(ls labels.LabelSet) []*Job {
+	if !ls[labels.BuildBazel].Valid {
+		return nil
+	}
+	return []*Job{bazelTestJob(ls)}
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// bun is installed in the base image, as the oven/bun images don't have the git and ssh
+// clients checkout needs
+const bunDockerImage = "cimg/base:stable"
+const bunVersion = "1.1.42"
+
+var installBunStep = config.Step{
+	Type: config.Run,
+	Name: "Install Bun",
+	Command: fmt.Sprintf(`curl -fsSL https://bun.sh/install | bash -s "bun-v%s"
+echo 'export PATH="$HOME/.bun/bin:$PATH"' >> "$BASH_ENV"`, bunVersion),
+}
+
+const bunCacheKeyCommand = "cat bun.lock* > /tmp/CIRCLECI_CACHE_KEY"
+const bunCacheKey = `bun-{{ checksum "/tmp/CIRCLECI_CACHE_KEY" }}`
+
+func bunInitialSteps(ls labels.LabelSet) []config.Step {
+	steps := []config.Step{checkoutStep(ls[labels.DepsNode]), installBunStep}
+	if !ls[labels.DepsNode].HasLockFile {
+		return append(steps, config.Step{
+			Type:    config.Run,
+			Name:    "Install dependencies",
+			Command: "bun install",
+		})
+	}
+	return append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Calculate cache key",
+			Command: bunCacheKeyCommand,
+		},
+		config.Step{
+			Type:     config.RestoreCache,
+			CacheKey: bunCacheKey,
+		},
+		config.Step{
+			Type:    config.Run,
+			Name:    "Install dependencies",
+			Command: "bun install --frozen-lockfile",
+		},
+		config.Step{
+			Type:     config.SaveCache,
+			CacheKey: bunCacheKey,
+			Path:     "~/.bun/install/cache",
+		})
+}
+
+func bunTestJob(ls labels.LabelSet) *Job {
+	steps := bunInitialSteps(ls)
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: "bun test --reporter=junit --reporter-outfile=test-results/junit.xml",
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: "test-results",
+		})
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-bun",
+			Comment:          "Install dependencies and run tests with Bun",
+			DockerImages:     []string{bunDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsNode]),
+			Steps:            steps,
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsNode, labels.PackageManagerBun),
+	}
+}
+
+func bunBuildJob(ls labels.LabelSet) *Job {
+	task := nodeBuildTask(ls)
+	if task == "" {
+		return nil
+	}
+
+	steps := bunInitialSteps(ls)
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Command: nodeRunCommand(ls, task),
+		},
+		createArtifactsDirStep,
+		copyNodeArtifactsStep,
+		storeArtifactsStep("node-build"))
+
+	return &Job{
+		Job: config.Job{
+			Name:             "build-bun",
+			Comment:          "Build project with Bun",
+			DockerImages:     []string{bunDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsNode]),
+			Steps:            steps,
+		},
+		Type:   ArtifactJob,
+		Labels: validLabelKeys(ls, labels.DepsNode, labels.PackageManagerBun),
+	}
+}
+
+// generateBunJobs replaces the node jobs of projects using Bun, which don't need the node orb
+func generateBunJobs(ls labels.LabelSet) []*Job {
+	jobs := []*Job{bunTestJob(ls)}
+	if buildJob := bunBuildJob(ls); buildJob != nil {
+		jobs = append(jobs, buildJob)
+	}
+	return jobs
+}
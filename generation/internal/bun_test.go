@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerateNodeJobs_bunWithoutLockFile(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.DepsNode: labels.Label{
+			Key:       labels.DepsNode,
+			Valid:     true,
+			LabelData: labels.LabelData{BasePath: "."},
+		},
+		labels.PackageManagerBun: labels.Label{
+			Key:       labels.PackageManagerBun,
+			Valid:     true,
+			LabelData: labels.LabelData{BasePath: "."},
+		},
+	}
+	jobs := GenerateNodeJobs(ls)
+	if len(jobs) != 1 {
+		t.Fatalf("got %d jobs, expected only the test job", len(jobs))
+	}
+	expectedSteps := []config.Step{
+		{Type: config.Checkout},
+		installBunStep,
+		{Type: config.Run, Name: "Install dependencies", Command: "bun install"},
+		{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: "bun test --reporter=junit --reporter-outfile=test-results/junit.xml",
+		},
+		{Type: config.StoreTestResults, Path: "test-results"},
+	}
+	if d := cmp.Diff(expectedSteps, jobs[0].Steps); d != "" {
+		t.Errorf("steps mismatch (-want +got):\n%s", d)
+	}
+	if jobs[0].Orbs != nil {
+		t.Errorf("got orbs %v, expected none", jobs[0].Orbs)
+	}
+}
+
+func TestGenerateNodeJobs_deno(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.DepsNode: labels.Label{Key: labels.DepsNode, Valid: true, LabelData: labels.LabelData{BasePath: "."}},
+		labels.DepsDeno: labels.Label{Key: labels.DepsDeno, Valid: true, LabelData: labels.LabelData{BasePath: "."}},
+	}
+	if jobs := GenerateNodeJobs(ls); jobs != nil {
+		t.Errorf("got %d jobs, expected deno to run the package.json of its project", len(jobs))
+	}
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// comes with both Leiningen and the Clojure CLI
+const clojureDockerImage = "cimg/clojure:1.11"
+
+// clojureBuildFile is the file the dependencies are declared in, which the cache is keyed on
+func clojureBuildFile(ls labels.LabelSet) string {
+	if ls[labels.PackageManagerLein].Valid {
+		return "project.clj"
+	}
+	return "deps.edn"
+}
+
+func clojureInitialSteps(ls labels.LabelSet) []config.Step {
+	return []config.Step{
+		checkoutStep(ls[labels.DepsClojure]),
+		{
+			Type:     config.RestoreCache,
+			CacheKey: clojureCacheKey(ls),
+		},
+	}
+}
+
+func clojureCacheKey(ls labels.LabelSet) string {
+	return fmt.Sprintf(`clojure-{{ checksum "%s" }}`, clojureBuildFile(ls))
+}
+
+func saveClojureCacheStep(ls labels.LabelSet) config.Step {
+	return config.Step{
+		Type:     config.SaveCache,
+		CacheKey: clojureCacheKey(ls),
+		Path:     "~/.m2",
+	}
+}
+
+func clojureTestJob(ls labels.LabelSet) *Job {
+	testCommand := "clojure -X:test"
+	if ls[labels.PackageManagerLein].Valid {
+		testCommand = "lein test"
+	}
+
+	steps := clojureInitialSteps(ls)
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: testCommand,
+		},
+		saveClojureCacheStep(ls))
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-clojure",
+			Comment:          "Download dependencies and run tests",
+			DockerImages:     []string{clojureDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsClojure]),
+			Steps:            steps,
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsClojure, labels.PackageManagerLein),
+	}
+}
+
+func clojureUberjarJob(ls labels.LabelSet) *Job {
+	steps := clojureInitialSteps(ls)
+	steps = append(steps,
+		createArtifactsDirStep,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Build an uberjar",
+			Command: fmt.Sprintf("lein uberjar && find target -name '*-standalone.jar' -exec cp {} %s \\;", artifactsPath),
+		},
+		storeArtifactsStep("jars"),
+		saveClojureCacheStep(ls))
+
+	return &Job{
+		Job: config.Job{
+			Name:             "build-clojure-uberjar",
+			Comment:          "Build an uberjar and store it as an artifact",
+			DockerImages:     []string{clojureDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsClojure]),
+			Steps:            steps,
+		},
+		Type:   ArtifactJob,
+		Labels: validLabelKeys(ls, labels.DepsClojure, labels.PackageManagerLein, labels.ArtifactLeinUberjar),
+	}
+}
+
+func GenerateClojureJobs(ls labels.LabelSet) (jobs []*Job) {
+	if !ls[labels.DepsClojure].Valid {
+		return nil
+	}
+
+	jobs = append(jobs, clojureTestJob(ls))
+	if ls[labels.ArtifactLeinUberjar].Valid {
+		jobs = append(jobs, clojureUberjarJob(ls))
+	}
+	return jobs
+}
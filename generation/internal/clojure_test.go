@@ -0,0 +1,28 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_clojureTestJob_toolsDeps(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.DepsClojure: labels.Label{
+			Key:       labels.DepsClojure,
+			Valid:     true,
+			LabelData: labels.LabelData{BasePath: "."},
+		},
+	}
+	expectedSteps := []config.Step{
+		{Type: config.Checkout},
+		{Type: config.RestoreCache, CacheKey: `clojure-{{ checksum "deps.edn" }}`},
+		{Type: config.Run, Name: "Run tests", Command: "clojure -X:test"},
+		{Type: config.SaveCache, CacheKey: `clojure-{{ checksum "deps.edn" }}`, Path: "~/.m2"},
+	}
+	if d := cmp.Diff(expectedSteps, clojureTestJob(ls).Steps); d != "" {
+		t.Errorf("steps mismatch (-want +got):\n%s", d)
+	}
+}
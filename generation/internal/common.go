@@ -19,6 +19,30 @@ func checkoutStep(depsLabel labels.Label) config.Step {
 	}
 }
 
+var submodulesStep = config.Step{
+	Type:    config.Run,
+	Name:    "Check out git submodules",
+	Command: "git submodule update --init --recursive",
+}
+
+// AddSubmoduleSteps adds a step checking out the git submodules after the checkout step of
+// each job, if submodulesLabel is valid
+func AddSubmoduleSteps(submodulesLabel labels.Label, jobs []*Job) {
+	if !submodulesLabel.Valid {
+		return
+	}
+	for _, j := range jobs {
+		var steps []config.Step
+		for _, step := range j.Steps {
+			steps = append(steps, step)
+			if step.Type == config.Checkout {
+				steps = append(steps, submodulesStep)
+			}
+		}
+		j.Steps = steps
+	}
+}
+
 func workingDirectory(depsLabel labels.Label) string {
 	if depsLabel.BasePath == "." {
 		return "."
@@ -26,6 +50,17 @@ func workingDirectory(depsLabel labels.Label) string {
 	return filepath.Join(defaultCheckoutDir, depsLabel.BasePath)
 }
 
+// validLabelKeys returns the keys, out of the given ones, of the labels in ls that are valid
+func validLabelKeys(ls labels.LabelSet, keys ...string) []string {
+	var validKeys []string
+	for _, k := range keys {
+		if ls[k].Valid {
+			validKeys = append(validKeys, k)
+		}
+	}
+	return validKeys
+}
+
 const artifactsPath = "~/artifacts"
 
 var createArtifactsDirStep = config.Step{
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+const cppDockerImage = "cimg/base:stable"
+
+const cppTestResultsPath = "/tmp/test-results"
+
+func installCppToolsStep(packages ...string) config.Step {
+	return config.Step{
+		Type:    config.Run,
+		Name:    "Install build tools",
+		Command: "sudo apt-get update && sudo apt-get install -y " + strings.Join(append([]string{"build-essential"}, packages...), " "),
+	}
+}
+
+// cppDependencySteps install Conan or vcpkg dependencies, restoring and saving their cache,
+// and return the packages they need and the CMake configure flags to use them
+func cppDependencySteps(ls labels.LabelSet) (packages []string, initial, final []config.Step, cmakeFlags string) {
+	if ls[labels.PackageManagerConan].Valid {
+		cacheKey := `conan-{{ checksum "/tmp/CIRCLECI_CACHE_KEY" }}`
+		initial = []config.Step{
+			{
+				Type:    config.Run,
+				Name:    "Calculate cache key",
+				Command: "cat conanfile.* > /tmp/CIRCLECI_CACHE_KEY",
+			},
+			{
+				Type:     config.RestoreCache,
+				CacheKey: cacheKey,
+			},
+			{
+				Type: config.Run,
+				Name: "Install dependencies",
+				Command: "sudo pip3 install conan && conan profile detect --exist-ok && " +
+					"conan install . --output-folder=build --build=missing",
+			},
+		}
+		final = []config.Step{{
+			Type:     config.SaveCache,
+			CacheKey: cacheKey,
+			Path:     "~/.conan2",
+		}}
+		return []string{"python3-pip"}, initial, final,
+			" -DCMAKE_TOOLCHAIN_FILE=build/conan_toolchain.cmake -DCMAKE_BUILD_TYPE=Release"
+	}
+	if ls[labels.PackageManagerVcpkg].Valid {
+		cacheKey := `vcpkg-{{ checksum "vcpkg.json" }}`
+		initial = []config.Step{
+			{
+				Type:     config.RestoreCache,
+				CacheKey: cacheKey,
+			},
+			{
+				Type:    config.Run,
+				Name:    "Install vcpkg",
+				Command: "git clone --depth 1 https://github.com/microsoft/vcpkg ~/vcpkg && ~/vcpkg/bootstrap-vcpkg.sh -disableMetrics",
+			},
+		}
+		final = []config.Step{{
+			Type:     config.SaveCache,
+			CacheKey: cacheKey,
+			Path:     "~/.cache/vcpkg/archives",
+		}}
+		return []string{"zip", "unzip", "pkg-config"}, initial, final, " -DCMAKE_TOOLCHAIN_FILE=$HOME/vcpkg/scripts/buildsystems/vcpkg.cmake"
+	}
+	return nil, nil, nil, ""
+}
+
+func cmakeTestJob(ls labels.LabelSet) *Job {
+	packages, dependencySteps, saveCacheSteps, cmakeFlags := cppDependencySteps(ls)
+
+	steps := []config.Step{
+		checkoutStep(ls[labels.BuildCMake]),
+		installCppToolsStep(append([]string{"cmake", "ninja-build"}, packages...)...),
+	}
+	steps = append(steps, dependencySteps...)
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Configure",
+			Command: "cmake -S . -B build -G Ninja" + cmakeFlags,
+		},
+		config.Step{
+			Type:    config.Run,
+			Name:    "Build",
+			Command: "cmake --build build",
+		},
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: "ctest --test-dir build --output-on-failure --output-junit " + cppTestResultsPath + "/junit.xml",
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: cppTestResultsPath,
+		})
+	steps = append(steps, saveCacheSteps...)
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-cmake",
+			Comment:          "Configure, build and run tests with CMake",
+			DockerImages:     []string{cppDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.BuildCMake]),
+			Steps:            steps,
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.BuildCMake, labels.PackageManagerConan, labels.PackageManagerVcpkg),
+	}
+}
+
+func mesonTestJob(ls labels.LabelSet) *Job {
+	return &Job{
+		Job: config.Job{
+			Name:             "test-meson",
+			Comment:          "Configure, build and run tests with Meson",
+			DockerImages:     []string{cppDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.BuildMeson]),
+			Steps: []config.Step{
+				checkoutStep(ls[labels.BuildMeson]),
+				installCppToolsStep("meson", "ninja-build"),
+				{
+					Type:    config.Run,
+					Name:    "Configure",
+					Command: "meson setup build",
+				},
+				{
+					Type:    config.Run,
+					Name:    "Run tests",
+					Command: "meson test -C build",
+				},
+				{
+					// meson writes the results as JUnit XML to build/meson-logs/testlog.junit.xml
+					Type: config.StoreTestResults,
+					Path: "build/meson-logs",
+				},
+			},
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.BuildMeson),
+	}
+}
+
+func makeTestJob(ls labels.LabelSet) *Job {
+	return &Job{
+		Job: config.Job{
+			Name:             "test-make",
+			Comment:          "Build and run tests with make",
+			DockerImages:     []string{cppDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.BuildMake]),
+			Steps: []config.Step{
+				checkoutStep(ls[labels.BuildMake]),
+				installCppToolsStep(),
+				{
+					Type:    config.Run,
+					Name:    "Run tests",
+					Command: "make test",
+				},
+			},
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.BuildMake),
+	}
+}
+
+func GenerateCppJobs(ls labels.LabelSet) (jobs []*Job) {
+	if ls[labels.BuildCMake].Valid {
+		jobs = append(jobs, cmakeTestJob(ls))
+	}
+	if ls[labels.BuildMeson].Valid {
+		jobs = append(jobs, mesonTestJob(ls))
+	}
+	if ls[labels.BuildMake].Valid {
+		jobs = append(jobs, makeTestJob(ls))
+	}
+	return jobs
+}
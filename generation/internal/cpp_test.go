@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_cmakeTestJob_vcpkg(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.BuildCMake: labels.Label{
+			Key:       labels.BuildCMake,
+			Valid:     true,
+			LabelData: labels.LabelData{BasePath: "."},
+		},
+		labels.PackageManagerVcpkg: labels.Label{
+			Key:       labels.PackageManagerVcpkg,
+			Valid:     true,
+			LabelData: labels.LabelData{BasePath: "."},
+		},
+	}
+	expectedSteps := []config.Step{
+		{Type: config.Checkout},
+		{
+			Type:    config.Run,
+			Name:    "Install build tools",
+			Command: "sudo apt-get update && sudo apt-get install -y build-essential cmake ninja-build zip unzip pkg-config",
+		},
+		{Type: config.RestoreCache, CacheKey: `vcpkg-{{ checksum "vcpkg.json" }}`},
+		{
+			Type:    config.Run,
+			Name:    "Install vcpkg",
+			Command: "git clone --depth 1 https://github.com/microsoft/vcpkg ~/vcpkg && ~/vcpkg/bootstrap-vcpkg.sh -disableMetrics",
+		},
+		{
+			Type:    config.Run,
+			Name:    "Configure",
+			Command: "cmake -S . -B build -G Ninja -DCMAKE_TOOLCHAIN_FILE=$HOME/vcpkg/scripts/buildsystems/vcpkg.cmake",
+		},
+		{Type: config.Run, Name: "Build", Command: "cmake --build build"},
+		{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: "ctest --test-dir build --output-on-failure --output-junit /tmp/test-results/junit.xml",
+		},
+		{Type: config.StoreTestResults, Path: "/tmp/test-results"},
+		{Type: config.SaveCache, CacheKey: `vcpkg-{{ checksum "vcpkg.json" }}`, Path: "~/.cache/vcpkg/archives"},
+	}
+	if d := cmp.Diff(expectedSteps, cmakeTestJob(ls).Steps); d != "" {
+		t.Errorf("steps mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestGenerateCppJobs(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.BuildMeson: labels.Label{Key: labels.BuildMeson, Valid: true, LabelData: labels.LabelData{BasePath: "."}},
+		labels.BuildMake:  labels.Label{Key: labels.BuildMake, Valid: true, LabelData: labels.LabelData{BasePath: "legacy"}},
+	}
+	var names []string
+	for _, job := range GenerateCppJobs(ls) {
+		names = append(names, job.Name)
+	}
+	if d := cmp.Diff([]string{"test-meson", "test-make"}, names); d != "" {
+		t.Errorf("jobs mismatch (-want +got):\n%s", d)
+	}
+}
+
+func Test_makeTestJob(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.BuildMake: labels.Label{Key: labels.BuildMake, Valid: true, LabelData: labels.LabelData{BasePath: "."}},
+	}
+	install := makeTestJob(ls).Steps[1]
+	if install.Command != "sudo apt-get update && sudo apt-get install -y build-essential" {
+		t.Errorf("got command %q, expected only build-essential to be installed", install.Command)
+	}
+}
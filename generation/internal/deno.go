@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// deno is installed in the base image, as the denoland/deno images don't have the git and
+// ssh clients checkout needs
+const denoDockerImage = "cimg/base:stable"
+const denoVersion = "2.1.4"
+
+// denoCachePath is the default DENO_DIR on Linux, where remote modules are downloaded to
+const denoCachePath = "~/.cache/deno"
+
+var installDenoStep = config.Step{
+	Type: config.Run,
+	Name: "Install Deno",
+	Command: fmt.Sprintf(`curl -fsSL https://deno.land/install.sh | sh -s v%s
+echo 'export PATH="$HOME/.deno/bin:$PATH"' >> "$BASH_ENV"`, denoVersion),
+}
+
+const denoCacheKey = `deno-{{ checksum "deno.lock" }}`
+
+func denoTestJob(ls labels.LabelSet) *Job {
+	label := ls[labels.DepsDeno]
+	steps := []config.Step{checkoutStep(label), installDenoStep}
+	if label.HasLockFile {
+		steps = append(steps, config.Step{
+			Type:     config.RestoreCache,
+			CacheKey: denoCacheKey,
+		})
+	}
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Check formatting",
+			Command: "deno fmt --check",
+		},
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run linter",
+			Command: "deno lint",
+		},
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: "deno test --allow-all --junit-path=test-results/junit.xml",
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: "test-results",
+		})
+	if label.HasLockFile {
+		steps = append(steps, config.Step{
+			Type:     config.SaveCache,
+			CacheKey: denoCacheKey,
+			Path:     denoCachePath,
+		})
+	}
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-deno",
+			Comment:          "Check formatting, lint and run tests with Deno",
+			DockerImages:     []string{denoDockerImage},
+			WorkingDirectory: workingDirectory(label),
+			Steps:            steps,
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsDeno),
+	}
+}
+
+func GenerateDenoJobs(ls labels.LabelSet) []*Job {
+	if !ls[labels.DepsDeno].Valid {
+		return nil
+	}
+	return []*Job{denoTestJob(ls)}
+}
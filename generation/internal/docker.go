@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// dockerRegistryContext is the context with the registry credentials, DOCKER_LOGIN and
+// DOCKER_PASSWORD, and DOCKER_REGISTRY, the registry and namespace to push to, like
+// ghcr.io/myorg, or only the namespace, like myorg, for Docker Hub
+const dockerRegistryContext = "docker-registry"
+
+// dockerPushCommand pushes only on the default branch, so that every branch builds the
+// image but only the default one publishes it. Like docker itself, it only takes the first
+// part of DOCKER_REGISTRY for the registry server if it looks like a host name.
+const dockerPushCommand = `if [ "<< pipeline.git.branch.is_default >>" != "true" ]; then
+  echo "Not on the default branch, skipping push"
+  exit 0
+fi
+server="${DOCKER_REGISTRY%%%%/*}"
+case "$server" in
+  *.*|*:*|localhost) ;;
+  *) server="" ;;
+esac
+echo "$DOCKER_PASSWORD" | docker login -u "$DOCKER_LOGIN" --password-stdin $server
+docker push "%s"`
+
+// dockerRepoName is the name of the repository, lowercased as image names must be
+const dockerRepoName = `$(echo "$CIRCLE_PROJECT_REPONAME" | tr '[:upper:]' '[:lower:]')`
+
+var invalidImageNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// dockerImageName returns the name of the image built from dockerfile: the name of the
+// compose service building it, or else the name of its dir, or rootName for the root dir,
+// with the suffix of Dockerfile.<suffix> or the prefix of <prefix>.Dockerfile.
+// In a project of a monorepo, the name of the project is used for its root dir, and as
+// prefix of the other names.
+func dockerImageName(label labels.Label, dockerfile string, rootName string) string {
+	var project string
+	if label.BasePath != "." && label.BasePath != "" {
+		project = sanitizeImageName(path.Base(label.BasePath))
+		rootName = project
+	}
+	withProject := func(name string) string {
+		if project == "" || name == project {
+			return name
+		}
+		return project + "-" + name
+	}
+
+	if service := label.Dependencies[dockerfile]; service != "" {
+		return withProject(sanitizeImageName(service))
+	}
+
+	name := rootName
+	if dir := path.Dir(dockerfile); dir != "." {
+		name = withProject(sanitizeImageName(path.Base(dir)))
+	}
+	base := path.Base(dockerfile)
+	if variant := strings.TrimPrefix(base, "Dockerfile."); variant != base {
+		name += "-" + sanitizeImageName(variant)
+	} else if variant = strings.TrimSuffix(base, ".Dockerfile"); variant != base {
+		name += "-" + sanitizeImageName(variant)
+	}
+	return name
+}
+
+func sanitizeImageName(name string) string {
+	return strings.Trim(invalidImageNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-._")
+}
+
+func dockerImageJob(label labels.Label, dockerfile string, jobName string) *Job {
+	imageName := dockerImageName(label, dockerfile, dockerRepoName)
+	image := fmt.Sprintf("$DOCKER_REGISTRY/%s:$CIRCLE_SHA1", imageName)
+
+	return &Job{
+		Job: config.Job{
+			Name:             jobName,
+			Comment:          fmt.Sprintf("Build the image of %s, and push it on the default branch", dockerfile),
+			DockerImages:     []string{"cimg/base:stable"},
+			WorkingDirectory: workingDirectory(label),
+			Steps: []config.Step{
+				checkoutStep(label),
+				{
+					Type:               config.SetupRemoteDocker,
+					DockerLayerCaching: true,
+				},
+				{
+					Type:    config.Run,
+					Name:    "Build image",
+					Command: fmt.Sprintf(`docker build -t "%s" -f %s %s`, image, dockerfile, label.Tasks[dockerfile]),
+				},
+				{
+					Type:    config.Run,
+					Name:    "Push image",
+					Command: fmt.Sprintf(dockerPushCommand, image),
+				},
+			},
+		},
+		Type:     ArtifactJob,
+		Labels:   []string{labels.ArtifactDockerImage},
+		Contexts: []string{dockerRegistryContext},
+	}
+}
+
+func GenerateDockerJobs(ls labels.LabelSet) (jobs []*Job) {
+	label := ls[labels.ArtifactDockerImage]
+	if !label.Valid {
+		return nil
+	}
+
+	dockerfiles := make([]string, 0, len(label.Tasks))
+	for dockerfile := range label.Tasks {
+		dockerfiles = append(dockerfiles, dockerfile)
+	}
+	sort.Strings(dockerfiles)
+
+	usedNames := make(map[string]bool)
+	for _, dockerfile := range dockerfiles {
+		jobName := "build-docker-image"
+		if len(dockerfiles) > 1 {
+			jobName += "-" + dockerImageName(label, dockerfile, "root")
+		}
+		// Dockerfiles in dirs with the same name, like a/web and b/web
+		baseName := jobName
+		for i := 2; usedNames[jobName]; i++ {
+			jobName = fmt.Sprintf("%s-%d", baseName, i)
+		}
+		usedNames[jobName] = true
+		jobs = append(jobs, dockerImageJob(label, dockerfile, jobName))
+	}
+	return jobs
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerateDockerJobs(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.ArtifactDockerImage: labels.Label{
+			Key:   labels.ArtifactDockerImage,
+			Valid: true,
+			LabelData: labels.LabelData{
+				BasePath: ".",
+				Tasks: map[string]string{
+					"Dockerfile":          ".",
+					"Dockerfile.dev":      ".",
+					"a/web/Dockerfile":    "a/web",
+					"b/web/Dockerfile":    "b/web",
+					"api/Dockerfile.prod": ".",
+					"tools/CI.Dockerfile": "tools",
+				},
+				Dependencies: map[string]string{"api/Dockerfile.prod": "API_server"},
+			},
+		},
+	}
+
+	type job struct {
+		Name     string
+		Build    string
+		Contexts []string
+	}
+	var got []job
+	for _, j := range GenerateDockerJobs(ls) {
+		got = append(got, job{Name: j.Name, Build: j.Steps[2].Command, Contexts: j.Contexts})
+	}
+
+	contexts := []string{"docker-registry"}
+	expected := []job{
+		{
+			Name:     "build-docker-image-root",
+			Build:    `docker build -t "$DOCKER_REGISTRY/$(echo "$CIRCLE_PROJECT_REPONAME" | tr '[:upper:]' '[:lower:]'):$CIRCLE_SHA1" -f Dockerfile .`,
+			Contexts: contexts,
+		},
+		{
+			Name:     "build-docker-image-root-dev",
+			Build:    `docker build -t "$DOCKER_REGISTRY/$(echo "$CIRCLE_PROJECT_REPONAME" | tr '[:upper:]' '[:lower:]')-dev:$CIRCLE_SHA1" -f Dockerfile.dev .`,
+			Contexts: contexts,
+		},
+		{
+			Name:     "build-docker-image-web",
+			Build:    `docker build -t "$DOCKER_REGISTRY/web:$CIRCLE_SHA1" -f a/web/Dockerfile a/web`,
+			Contexts: contexts,
+		},
+		{
+			Name:     "build-docker-image-api_server",
+			Build:    `docker build -t "$DOCKER_REGISTRY/api_server:$CIRCLE_SHA1" -f api/Dockerfile.prod .`,
+			Contexts: contexts,
+		},
+		{
+			Name:     "build-docker-image-web-2",
+			Build:    `docker build -t "$DOCKER_REGISTRY/web:$CIRCLE_SHA1" -f b/web/Dockerfile b/web`,
+			Contexts: contexts,
+		},
+		{
+			Name:     "build-docker-image-tools-ci",
+			Build:    `docker build -t "$DOCKER_REGISTRY/tools-ci:$CIRCLE_SHA1" -f tools/CI.Dockerfile tools`,
+			Contexts: contexts,
+		},
+	}
+	if d := cmp.Diff(expected, got); d != "" {
+		t.Errorf("jobs mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestGenerateDockerJobs_project(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.ArtifactDockerImage: labels.Label{
+			Key:   labels.ArtifactDockerImage,
+			Valid: true,
+			LabelData: labels.LabelData{
+				BasePath: "apps/Frontend",
+				Tasks:    map[string]string{"Dockerfile": ".", "admin/Dockerfile": "admin"},
+			},
+		},
+	}
+
+	type job struct {
+		Name             string
+		WorkingDirectory string
+		Build            string
+	}
+	var got []job
+	for _, j := range GenerateDockerJobs(ls) {
+		got = append(got, job{Name: j.Name, WorkingDirectory: j.WorkingDirectory, Build: j.Steps[2].Command})
+	}
+
+	expected := []job{
+		{
+			Name:             "build-docker-image-frontend",
+			WorkingDirectory: "~/project/apps/Frontend",
+			Build:            `docker build -t "$DOCKER_REGISTRY/frontend:$CIRCLE_SHA1" -f Dockerfile .`,
+		},
+		{
+			Name:             "build-docker-image-frontend-admin",
+			WorkingDirectory: "~/project/apps/Frontend",
+			Build:            `docker build -t "$DOCKER_REGISTRY/frontend-admin:$CIRCLE_SHA1" -f admin/Dockerfile admin`,
+		},
+	}
+	if d := cmp.Diff(expected, got); d != "" {
+		t.Errorf("jobs mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// latest LTS version
+const dotnetFallbackVersion = "8.0"
+const nugetCacheKey = `nuget-{{ checksum "/tmp/CIRCLECI_CACHE_KEY" }}`
+const dotnetTestResultsPath = "/tmp/test-results"
+
+// dotnetJUnitLogger is the package of the junit logger of dotnet test
+const dotnetJUnitLogger = "JunitXml.TestLogger"
+
+// dotnetAddJUnitLoggerCommand adds dotnetJUnitLogger to the projects referencing a test
+// framework, without restoring, as the restore step does
+var dotnetAddJUnitLoggerCommand = fmt.Sprintf(
+	`for project in $(grep -rlE --include='*.csproj' --include='*.fsproj' 'Include="(xunit|NUnit|MSTest)' .); do
+  dotnet add "$project" package %s --no-restore
+done`, dotnetJUnitLogger)
+
+// dotnetImage returns the .NET SDK image for the major.minor version of the SDK in the
+// deps label, like mcr.microsoft.com/dotnet/sdk:8.0 for 8.0.100
+func dotnetImage(ls labels.LabelSet) string {
+	version := dotnetFallbackVersion
+	if parts := strings.Split(ls[labels.DepsDotnet].Version, "."); len(parts) >= 2 {
+		version = parts[0] + "." + parts[1]
+	}
+	return "mcr.microsoft.com/dotnet/sdk:" + version
+}
+
+// dotnetInitialSteps checks out the code, runs the given steps, which can add packages,
+// and restores the packages
+func dotnetInitialSteps(ls labels.LabelSet, beforeRestore ...config.Step) []config.Step {
+	depsLabel := ls[labels.DepsDotnet]
+	restoreCommand := "dotnet restore"
+	// packages added before restoring are not in the lock files
+	if depsLabel.HasLockFile && len(beforeRestore) == 0 {
+		restoreCommand += " --locked-mode"
+	}
+
+	steps := append([]config.Step{checkoutStep(depsLabel)}, beforeRestore...)
+	return append(steps, []config.Step{
+		{
+			Type: config.Run,
+			Name: "Calculate cache key",
+			Command: `find . -name 'packages.lock.json' -o -name '*.csproj' -o -name '*.fsproj' | \
+        sort | xargs cat > /tmp/CIRCLECI_CACHE_KEY`,
+		},
+		{
+			Type:     config.RestoreCache,
+			CacheKey: nugetCacheKey,
+		},
+		{
+			Type:    config.Run,
+			Name:    "Restore packages",
+			Command: restoreCommand,
+		},
+		{
+			Type:     config.SaveCache,
+			CacheKey: nugetCacheKey,
+			Path:     "~/.nuget/packages",
+		},
+	}...)
+}
+
+func dotnetTestJob(ls labels.LabelSet) *Job {
+	var addLoggerSteps []config.Step
+	if _, ok := ls[labels.DepsDotnet].Dependencies[dotnetJUnitLogger]; !ok {
+		addLoggerSteps = append(addLoggerSteps, config.Step{
+			Type:    config.Run,
+			Name:    "Add the junit logger to the test projects",
+			Command: dotnetAddJUnitLoggerCommand,
+		})
+	}
+	steps := dotnetInitialSteps(ls, addLoggerSteps...)
+
+	steps = append(steps, []config.Step{{
+		Type: config.Run,
+		Name: "Run tests",
+		Command: fmt.Sprintf(
+			`dotnet test --no-restore --logger "junit;LogFilePath=%s/{assembly}.xml"`,
+			dotnetTestResultsPath),
+	}, {
+		Type: config.StoreTestResults,
+		Path: dotnetTestResultsPath,
+	}}...)
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-dotnet",
+			Comment:          "Restore packages and run tests",
+			DockerImages:     []string{dotnetImage(ls)},
+			WorkingDirectory: workingDirectory(ls[labels.DepsDotnet]),
+			Steps:            steps,
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsDotnet, labels.TestDotnet),
+	}
+}
+
+func dotnetPublishJob(ls labels.LabelSet) *Job {
+	steps := dotnetInitialSteps(ls)
+
+	executables := ls[labels.ArtifactDotnetExecutable].Tasks
+	projects := make([]string, 0, len(executables))
+	for project := range executables {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	var commands []string
+	for _, project := range projects {
+		commands = append(commands, fmt.Sprintf(
+			"dotnet publish %s --no-restore --configuration Release --output %s/%s",
+			project, artifactsPath, executables[project]))
+	}
+
+	steps = append(steps,
+		createArtifactsDirStep,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Publish",
+			Command: strings.Join(commands, "\n"),
+		},
+		storeArtifactsStep("publish"))
+
+	return &Job{
+		Job: config.Job{
+			Name:             "publish-dotnet",
+			Comment:          "Publish the application and store it as an artifact",
+			DockerImages:     []string{dotnetImage(ls)},
+			WorkingDirectory: workingDirectory(ls[labels.DepsDotnet]),
+			Steps:            steps,
+		},
+		Type:   ArtifactJob,
+		Labels: validLabelKeys(ls, labels.DepsDotnet, labels.ArtifactDotnetExecutable),
+	}
+}
+
+func GenerateDotnetJobs(ls labels.LabelSet) (jobs []*Job) {
+	if !ls[labels.DepsDotnet].Valid {
+		return nil
+	}
+
+	if ls[labels.TestDotnet].Valid {
+		jobs = append(jobs, dotnetTestJob(ls))
+	}
+	if ls[labels.ArtifactDotnetExecutable].Valid {
+		jobs = append(jobs, dotnetPublishJob(ls))
+	}
+	return jobs
+}
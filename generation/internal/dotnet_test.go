@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+func Test_dotnetImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		version       string
+		expectedImage string
+	}{
+		{name: "sdk version in global.json", version: "6.0.417", expectedImage: "mcr.microsoft.com/dotnet/sdk:6.0"},
+		{name: "target framework version", version: "7.0", expectedImage: "mcr.microsoft.com/dotnet/sdk:7.0"},
+		{name: "no version - use fallback", version: "", expectedImage: "mcr.microsoft.com/dotnet/sdk:8.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := labels.LabelSet{
+				labels.DepsDotnet: labels.Label{
+					Key:       labels.DepsDotnet,
+					LabelData: labels.LabelData{Version: tt.version},
+				},
+			}
+			if got := dotnetImage(ls); got != tt.expectedImage {
+				t.Errorf("got %q, expected %q", got, tt.expectedImage)
+			}
+		})
+	}
+}
+
+func Test_dotnetTestJob_junitLogger(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		dependencies    map[string]string
+		expectedCommand string
+	}{
+		{name: "logger missing", dependencies: map[string]string{"xunit": "2.6.2"}, expectedCommand: dotnetAddJUnitLoggerCommand},
+		{name: "logger referenced", dependencies: map[string]string{dotnetJUnitLogger: "3.1.12"}, expectedCommand: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := labels.LabelSet{
+				labels.DepsDotnet: labels.Label{
+					Key:       labels.DepsDotnet,
+					Valid:     true,
+					LabelData: labels.LabelData{BasePath: ".", Dependencies: tt.dependencies},
+				},
+			}
+			var got string
+			for _, step := range dotnetTestJob(ls).Steps {
+				if step.Command == dotnetAddJUnitLoggerCommand {
+					got = step.Command
+				}
+			}
+			if got != tt.expectedCommand {
+				t.Errorf("got command %q, expected %q", got, tt.expectedCommand)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+const pathFilteringOrb = "circleci/path-filtering@1.0.0"
+
+// ContinuationConfigPath is the path, in the repo, of the continuation config the setup
+// config built by BuildDynamicConfig continues the pipeline with
+const ContinuationConfigPath = ".circleci/continue_config.yml"
+
+// baseRevisionParameter is the pipeline parameter of the setup config with the revision
+// changes are compared with. It defaults to the default branch, rather than to
+// pipeline.git.base_revision, which is empty for the first push of a branch.
+var baseRevisionParameter = config.Parameter{Name: "base-revision", Type: "string", Default: "main"}
+
+// BuildDynamicConfig builds configs for the jobs of several projects, so that the jobs of
+// a project only run when files under its BasePath change:
+//   - a setup config, that uses the path-filtering orb to set a pipeline parameter for
+//     each project with changes and continues the pipeline with the continuation config
+//   - the continuation config, with a workflow per project that only runs if its
+//     parameter is set
+//
+// Workflows can't require the jobs of other workflows, so each workflow has its own
+// commented out deploy job, requiring the jobs of its project: a change to several projects
+// runs the deploy job once for each of them, if it is uncommented in their workflows.
+//
+// See https://circleci.com/docs/dynamic-config/
+func BuildDynamicConfig(projects []labels.Project, jobs []*Job) (setup config.Config, continuation config.Config) {
+	ls, stacks := mergeProjects(projects)
+	deployJob := buildDeployJob(ls)
+
+	var mappings []string
+	var parameters []config.Parameter
+	var workflows []*config.Workflow
+	for _, p := range projects {
+		var projectJobs []*Job
+		for _, j := range jobs {
+			if j.Project == p.BasePath {
+				projectJobs = append(projectJobs, j)
+			}
+		}
+		if len(projectJobs) == 0 {
+			continue
+		}
+
+		parameter := fmt.Sprintf("run-%s", p.Name())
+		mappings = append(mappings,
+			fmt.Sprintf("%s %s true", changedFilesRegexp(p.BasePath, nestedProjects(p, projects)), parameter))
+		parameters = append(parameters, config.Parameter{
+			Name:    parameter,
+			Type:    "boolean",
+			Default: "false",
+		})
+
+		projectWorkflows := buildWorkflows(p.Name(), append(projectJobs, deployJob))
+		for _, w := range projectWorkflows {
+			w.When = fmt.Sprintf("<< pipeline.parameters.%s >>", parameter)
+		}
+		workflows = append(workflows, projectWorkflows...)
+	}
+
+	jobs = append(jobs, deployJob)
+	configJobs := make([]*config.Job, len(jobs))
+	for i := range jobs {
+		configJobs[i] = &jobs[i].Job
+	}
+
+	continuation = config.Config{
+		Comment: fmt.Sprintf("This config was automatically generated from your source code\n"+
+			"Stacks detected: %s\n"+
+			"Each workflow deploys after the jobs of its project, so a change to several projects "+
+			"deploys once for each of them", stacks),
+		Parameters: parameters,
+		Workflows:  workflows,
+		Jobs:       configJobs,
+		Orbs:       buildOrbs(jobs),
+	}
+
+	setup = config.Config{
+		Comment: fmt.Sprintf("This setup config was automatically generated from your source code\n"+
+			"It continues the pipeline with %s, running the workflows of the projects with changes\n"+
+			"since the revision of the %s pipeline parameter, %s by default",
+			ContinuationConfigPath, baseRevisionParameter.Name, baseRevisionParameter.Default),
+		Setup:      true,
+		Orbs:       []config.Orb{{Name: "path-filtering", RegistryKey: pathFilteringOrb}},
+		Parameters: []config.Parameter{baseRevisionParameter},
+		Workflows: []*config.Workflow{{
+			Name: "setup",
+			Jobs: []config.WorkflowJob{{
+				Job: &config.Job{Name: "path-filtering/filter"},
+				Parameters: config.OrbCommandParameters{
+					"base-revision": fmt.Sprintf("<< pipeline.parameters.%s >>", baseRevisionParameter.Name),
+					"config-path":   ContinuationConfigPath,
+					"mapping":       strings.Join(mappings, "\n"),
+				},
+			}},
+		}},
+	}
+
+	return setup, continuation
+}
+
+// nestedProjects returns the BasePaths of the projects under p, whose files are not part of p
+func nestedProjects(p labels.Project, projects []labels.Project) []string {
+	var nested []string
+	for _, other := range projects {
+		if other.BasePath == p.BasePath || other.BasePath == "." {
+			continue
+		}
+		if p.BasePath == "." || strings.HasPrefix(other.BasePath, p.BasePath+"/") {
+			nested = append(nested, other.BasePath)
+		}
+	}
+	return nested
+}
+
+// changedFilesRegexp returns a regexp for the path-filtering orb matching changes to
+// files under basePath, except for the ones under the nested projects.
+// The orb matches the whole path of changed files with Python's re module, which supports
+// the negative lookahead excluding nested projects.
+func changedFilesRegexp(basePath string, nested []string) string {
+	pattern := ".*"
+	if basePath != "." && basePath != "" {
+		pattern = regexp.QuoteMeta(basePath) + "/.*"
+	}
+	if len(nested) == 0 {
+		return pattern
+	}
+	excluded := make([]string, len(nested))
+	for i, n := range nested {
+		excluded[i] = regexp.QuoteMeta(n) + "/"
+	}
+	return fmt.Sprintf("(?!%s)%s", strings.Join(excluded, "|"), pattern)
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+const elixirFallbackVersion = "1.15"
+const erlangFallbackVersion = "26"
+const mixCacheKey = `mix-{{ checksum "mix.lock" }}`
+const rebarCacheKey = `rebar3-{{ checksum "rebar.lock" }}`
+
+// hasMixDep returns true if the mix.exs of the project depends on dep
+func hasMixDep(ls labels.LabelSet, dep string) bool {
+	return ls[labels.DepsElixir].Dependencies[dep] != ""
+}
+
+func elixirImage(ls labels.LabelSet) string {
+	version := ls[labels.DepsElixir].Version
+	if version == "" {
+		version = elixirFallbackVersion
+	}
+	return "cimg/elixir:" + version
+}
+
+// erlangImage returns the official Erlang image for the major OTP version, which comes
+// with rebar3
+func erlangImage(ls labels.LabelSet) string {
+	version := erlangFallbackVersion
+	if otp := ls[labels.DepsErlang].Version; otp != "" {
+		version = strings.Split(otp, ".")[0]
+	}
+	return "erlang:" + version
+}
+
+func elixirTestJob(ls labels.LabelSet) *Job {
+	depsLabel := ls[labels.DepsElixir]
+	images := []string{elixirImage(ls)}
+	steps := []config.Step{checkoutStep(depsLabel)}
+
+	if depsLabel.HasLockFile {
+		steps = append(steps, config.Step{Type: config.RestoreCache, CacheKey: mixCacheKey})
+	}
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Install hex and rebar",
+			Command: "mix local.hex --force && mix local.rebar --force",
+		},
+		config.Step{
+			Type:    config.Run,
+			Name:    "Install dependencies",
+			Command: "mix deps.get",
+		})
+	if depsLabel.HasLockFile {
+		steps = append(steps, config.Step{Type: config.SaveCache, CacheKey: mixCacheKey, Path: "deps"})
+	}
+
+	// Phoenix projects create and migrate the database in the test alias of mix.exs
+	if hasMixDep(ls, "ecto_sql") && hasMixDep(ls, "postgrex") {
+		images = append(images, postgresImage)
+
+		steps = append(steps,
+			config.Step{
+				Type:    config.Run,
+				Name:    "wait for DB",
+				Command: "dockerize -wait tcp://localhost:5432 -timeout 1m"})
+	}
+
+	// without the dependency, the formatter is installed as an archive, which mix loads in
+	// every project
+	if !hasMixDep(ls, "junit_formatter") {
+		steps = append(steps,
+			config.Step{
+				Type:    config.Run,
+				Name:    "Install JUnit formatter",
+				Command: "mix archive.install hex junit_formatter --force",
+			})
+	}
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: "mix test --formatter JUnitFormatter --formatter ExUnit.CLIFormatter",
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: "_build/test/lib",
+		})
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-elixir",
+			Comment:          "Install dependencies and run tests",
+			Steps:            steps,
+			DockerImages:     images,
+			WorkingDirectory: workingDirectory(depsLabel),
+			Environment: map[string]string{
+				"MIX_ENV": "test"},
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsElixir),
+	}
+}
+
+func erlangTestJob(ls labels.LabelSet) *Job {
+	depsLabel := ls[labels.DepsErlang]
+	steps := []config.Step{checkoutStep(depsLabel)}
+
+	if depsLabel.HasLockFile {
+		steps = append(steps, config.Step{Type: config.RestoreCache, CacheKey: rebarCacheKey})
+	}
+	steps = append(steps, config.Step{
+		Type:    config.Run,
+		Name:    "Run tests",
+		Command: "rebar3 do eunit, ct",
+	})
+	if depsLabel.HasLockFile {
+		steps = append(steps, config.Step{Type: config.SaveCache, CacheKey: rebarCacheKey, Path: "~/.cache/rebar3"})
+	}
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-erlang",
+			Comment:          "Run eunit and common test suites",
+			Steps:            steps,
+			DockerImages:     []string{erlangImage(ls)},
+			WorkingDirectory: workingDirectory(depsLabel),
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsErlang),
+	}
+}
+
+func GenerateElixirJobs(ls labels.LabelSet) (jobs []*Job) {
+	if ls[labels.DepsElixir].Valid {
+		jobs = append(jobs, elixirTestJob(ls))
+	}
+	if ls[labels.DepsErlang].Valid {
+		jobs = append(jobs, erlangTestJob(ls))
+	}
+	return jobs
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_erlangImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		version       string
+		expectedImage string
+	}{
+		{name: "otp version in .tool-versions", version: "25.3.2", expectedImage: "erlang:25"},
+		{name: "no version - use fallback", version: "", expectedImage: "erlang:26"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := labels.LabelSet{
+				labels.DepsErlang: labels.Label{
+					Key:       labels.DepsErlang,
+					LabelData: labels.LabelData{Version: tt.version},
+				},
+			}
+			if got := erlangImage(ls); got != tt.expectedImage {
+				t.Errorf("got %q, expected %q", got, tt.expectedImage)
+			}
+		})
+	}
+}
+
+func Test_elixirTestJob(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.DepsElixir: labels.Label{
+			Key:       labels.DepsElixir,
+			Valid:     true,
+			LabelData: labels.LabelData{BasePath: "."},
+		},
+	}
+	job := elixirTestJob(ls)
+	if len(job.DockerImages) != 1 || job.DockerImages[0] != "cimg/elixir:1.15" {
+		t.Errorf("got images %v, expected only the fallback elixir image", job.DockerImages)
+	}
+	expectedSteps := []config.Step{
+		{
+			Type:    config.Run,
+			Name:    "Install JUnit formatter",
+			Command: "mix archive.install hex junit_formatter --force",
+		},
+		{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: "mix test --formatter JUnitFormatter --formatter ExUnit.CLIFormatter",
+		},
+		{Type: config.StoreTestResults, Path: "_build/test/lib"},
+	}
+	if d := cmp.Diff(expectedSteps, job.Steps[len(job.Steps)-3:]); d != "" {
+		t.Errorf("steps mismatch (-want +got):\n%s", d)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
 )
 
+const goDockerImage = "cimg/go:1.20"
+
 const privateModInstructions = `echo "go mod download will fail if you have private repositories 
 One way to fix this for private go modules that are hosted in github:
   1. Add a GITHUB_TOKEN and GITHUB_USER to an org context. Please refer to https://circleci.com/docs/contexts/ for more informaiton on how to use contexts.
@@ -45,7 +47,7 @@ func goInitialSteps(ls labels.LabelSet) []config.Step {
 			Name:    "Print go mod help instructions",
 			Command: privateModInstructions,
 			When:    config.WhenTypeOnFail,
-		}, 
+		},
 		config.Step{
 			Type:     config.SaveCache,
 			CacheKey: goCacheKey,
@@ -70,11 +72,12 @@ func goTestJob(ls labels.LabelSet) *Job {
 		Job: config.Job{
 			Name:             "test-go",
 			Comment:          "Install go modules and run tests",
-			DockerImages:     []string{"cimg/go:1.20"},
+			DockerImages:     []string{goDockerImage},
 			WorkingDirectory: workingDirectory(ls[labels.DepsGo]),
 			Steps:            steps,
 		},
-		Type: TestJob,
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsGo),
 	}
 }
 
@@ -94,10 +97,11 @@ func goBuildJob(ls labels.LabelSet) *Job {
 		Job: config.Job{
 			Name:         "build-go-executables",
 			Comment:      "Build go executables and store them as artifacts",
-			DockerImages: []string{"cimg/go:1.20"},
+			DockerImages: []string{goDockerImage},
 			Steps:        steps,
 		},
-		Type: ArtifactJob,
+		Type:   ArtifactJob,
+		Labels: validLabelKeys(ls, labels.DepsGo, labels.ArtifactGoExecutable),
 	}
 }
 
@@ -8,6 +8,12 @@ import (
 // latest LTS version
 const javaDockerImage = "cimg/openjdk:17.0"
 
+// javaCacheKeyCommand writes the build files of Maven and Gradle projects to a file,
+// for javaCacheKey to change when they do
+const javaCacheKeyCommand = `find . -name 'pom.xml' -o -name 'gradlew*' -o -name '*.gradle*' | \
+        sort | xargs cat > /tmp/CIRCLECI_CACHE_KEY`
+const javaCacheKey = `cache-{{ checksum "/tmp/CIRCLECI_CACHE_KEY" }}`
+
 func javaTestJob(ls labels.LabelSet) *Job {
 	var cachePath string
 	var testCommand string
@@ -25,20 +31,16 @@ func javaTestJob(ls labels.LabelSet) *Job {
 		testResultsPath = "target/surefire-reports"
 	}
 
-	cacheKeyCalcCommand := `find . -name 'pom.xml' -o -name 'gradlew*' -o -name '*.gradle*' | \
-        sort | xargs cat > /tmp/CIRCLECI_CACHE_KEY`
-	cacheKey := `cache-{{ checksum "/tmp/CIRCLECI_CACHE_KEY" }}`
-
 	steps := []config.Step{
 		checkoutStep(ls[labels.DepsJava]),
 		{
 			Type:    config.Run,
 			Name:    "Calculate cache key",
-			Command: cacheKeyCalcCommand,
+			Command: javaCacheKeyCommand,
 		},
 		{
 			Type:     config.RestoreCache,
-			CacheKey: cacheKey,
+			CacheKey: javaCacheKey,
 		},
 		{
 			Type:    config.Run,
@@ -50,7 +52,7 @@ func javaTestJob(ls labels.LabelSet) *Job {
 		},
 		{
 			Type:     config.SaveCache,
-			CacheKey: cacheKey,
+			CacheKey: javaCacheKey,
 			Path:     cachePath,
 		},
 	}
@@ -70,7 +72,8 @@ func javaTestJob(ls labels.LabelSet) *Job {
 			WorkingDirectory: workingDirectory(ls[labels.DepsJava]),
 			Steps:            steps,
 		},
-		Type: TestJob,
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsJava, labels.ToolGradle),
 	}
 }
 
@@ -78,6 +81,12 @@ func GenerateJavaJobs(ls labels.LabelSet) (jobs []*Job) {
 	if !ls[labels.DepsJava].Valid {
 		return nil
 	}
+	// Android and Kotlin Multiplatform builds need their own jobs
+	for _, key := range []string{labels.DepsAndroid, labels.DepsKotlinMultiplatform} {
+		if ls[key].Valid && ls[key].BasePath == ls[labels.DepsJava].BasePath {
+			return nil
+		}
+	}
 
 	return append(jobs, javaTestJob(ls))
 }
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/CircleCI-Public/circleci-config/config"
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
@@ -15,6 +16,7 @@ const (
 	TestJob Type = iota // generic test job
 	ArtifactJob
 	DeployJob
+	LintJob // runs linters and formatters, in parallel with the tests
 )
 
 type Job struct {
@@ -23,11 +25,46 @@ type Job struct {
 	// map of orb name (e.g. "slack") to registry key (e.g. "circleci/slack@4.12.5")
 	// for orbs required by this job
 	Orbs map[string]string
+	// keys of the labels that caused this job to be generated
+	Labels []string
+	// BasePath of the project the job was generated for, when there are several
+	Project string
+	// contexts the job needs in workflows, for secrets like registry credentials
+	Contexts []string
 }
 
 func BuildConfig(ls labels.LabelSet, jobs []*Job) config.Config {
+	return buildConfig(ls, ls.String(), jobs)
+}
+
+// BuildProjectsConfig is like BuildConfig, for the jobs of several projects. Labels that
+// are not specific to a project, like cicd ones, are taken from the first project that has them.
+func BuildProjectsConfig(projects []labels.Project, jobs []*Job) config.Config {
+	ls, stacks := mergeProjects(projects)
+	return buildConfig(ls, stacks, jobs)
+}
+
+// mergeProjects returns a LabelSet with the first instance of every key in projects, and
+// the list of all labels in projects for the config comment
+func mergeProjects(projects []labels.Project) (labels.LabelSet, string) {
+	merged := make(labels.LabelSet)
+	var stacks []string
+	for _, p := range projects {
+		for k, label := range p.Labels {
+			if _, ok := merged[k]; !ok {
+				merged[k] = label
+			}
+			stacks = append(stacks, label.String())
+		}
+	}
+	sort.Strings(stacks)
+	return merged, strings.Join(stacks, ",")
+}
+
+// buildConfig builds a config with jobs, stacks is the list of labels for the comment
+func buildConfig(ls labels.LabelSet, stacks string, jobs []*Job) config.Config {
 	if len(jobs) == 0 {
-		return buildFallbackConfig(ls)
+		return buildFallbackConfig(ls, stacks)
 	}
 
 	jobs = addStubJobs(ls, jobs)
@@ -38,11 +75,11 @@ func BuildConfig(ls labels.LabelSet, jobs []*Job) config.Config {
 		configJobs[i] = &jobs[i].Job
 	}
 
-	workflows := buildWorkflows(jobs)
+	workflows := buildWorkflows("", jobs)
 
 	return config.Config{
 		Comment: fmt.Sprintf("This config was automatically generated from your source code\n"+
-			"Stacks detected: %s", ls.String()),
+			"Stacks detected: %s", stacks),
 		Workflows: workflows,
 		Jobs:      configJobs,
 		Orbs:      buildOrbs(jobs),
@@ -60,7 +97,7 @@ func buildDeployJob(ls labels.LabelSet) *Job {
 	return &deployJob
 }
 
-func buildFallbackConfig(ls labels.LabelSet) config.Config {
+func buildFallbackConfig(ls labels.LabelSet, stacks string) config.Config {
 	deployJob := buildDeployJob(ls)
 	deployJob.Comment = ""
 
@@ -69,7 +106,7 @@ This is a generic template to serve as a base for your custom config
 See: https://circleci.com/docs/configuration-reference`
 	if len(ls) > 0 {
 		comment = fmt.Sprintf("%s\n"+
-			"Stacks detected: %s", comment, ls.String())
+			"Stacks detected: %s", comment, stacks)
 
 	}
 
@@ -134,7 +171,7 @@ func addStubJobs(ls labels.LabelSet, jobs []*Job) []*Job {
 		jobTypesPresent[j.Type] = true
 	}
 
-	if !jobTypesPresent[TestJob] && !jobTypesPresent[ArtifactJob] {
+	if !jobTypesPresent[TestJob] && !jobTypesPresent[ArtifactJob] && !jobTypesPresent[LintJob] {
 		jobs = append(jobs, &stubTestJob)
 	}
 	if !jobTypesPresent[DeployJob] {
@@ -168,13 +205,15 @@ func buildOrbs(jobs []*Job) []config.Orb {
 	return orbs
 }
 
-func buildWorkflows(jobs []*Job) []*config.Workflow {
+// buildWorkflows returns a workflow for jobs, adding nameSuffix to its name if not empty
+func buildWorkflows(nameSuffix string, jobs []*Job) []*config.Workflow {
 	// DeployJobs are added, but commented out
 	workflowJobs := make([]config.WorkflowJob, len(jobs))
 	for i, j := range jobs {
 		workflowJobs[i].Job = &j.Job
 		workflowJobs[i].CommentedOut = j.Type == DeployJob
 		workflowJobs[i].Requires = workflowJobRequires(j, jobs)
+		workflowJobs[i].Contexts = j.Contexts
 	}
 
 	name := "build"
@@ -184,6 +223,9 @@ func buildWorkflows(jobs []*Job) []*config.Workflow {
 			name = "build-and-test"
 		}
 	}
+	if nameSuffix != "" {
+		name = fmt.Sprintf("%s-%s", name, nameSuffix)
+	}
 
 	return []*config.Workflow{{
 		Name: name,
@@ -195,16 +237,23 @@ func workflowJobRequires(job *Job, allJobs []*Job) []*config.Job {
 	jobsByType := getJobsByType(allJobs)
 
 	if job.Type == ArtifactJob {
-		return jobsByType[TestJob]
+		// artifacts only need the tests of their own project to pass
+		var testJobs []*config.Job
+		for _, j := range allJobs {
+			if j.Type == TestJob && j.Project == job.Project {
+				testJobs = append(testJobs, &j.Job)
+			}
+		}
+		return testJobs
 	}
 
 	if job.Type == DeployJob {
 		// if there are artifact jobs, it's sufficient to depend on them,
 		// as they already depend on any test jobs themselves
 		if len(jobsByType[ArtifactJob]) > 0 {
-			return jobsByType[ArtifactJob]
+			return append(jobsByType[ArtifactJob], jobsByType[LintJob]...)
 		}
-		return jobsByType[TestJob]
+		return append(jobsByType[TestJob], jobsByType[LintJob]...)
 	}
 
 	return []*config.Job{}
@@ -0,0 +1,301 @@
+package internal
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// lintResultsPath is where the linters that can write JUnit XML write it
+const lintResultsPath = "lint-results"
+
+// versions of the linters the lint jobs install, pinned so that their checks don't change
+// from one run to the next
+const (
+	golangciLintVersion         = "v1.55.2"
+	ruffVersion                 = "0.1.9"
+	flake8Version               = "6.1.0"
+	flake8JUnitFormatterVersion = "0.0.6"
+	blackVersion                = "23.12.1"
+)
+
+var createLintResultsDirStep = config.Step{
+	Type:    config.Run,
+	Name:    "Create the " + lintResultsPath + " directory",
+	Command: "mkdir -p " + lintResultsPath,
+}
+
+var storeLintResultsStep = config.Step{
+	Type: config.StoreTestResults,
+	Path: lintResultsPath,
+}
+
+func lintStep(name string, command string) config.Step {
+	return config.Step{
+		Type:    config.Run,
+		Name:    name,
+		Command: command,
+	}
+}
+
+var majorVersionPattern = regexp.MustCompile(`\d+`)
+
+func nodeLintJob(ls labels.LabelSet) *Job {
+	var steps []config.Step
+	if ls[labels.PackageManagerBun].Valid {
+		steps = bunInitialSteps(ls)
+	} else {
+		steps = nodeInitialSteps(ls)
+	}
+
+	if ls[labels.LintESLint].Valid {
+		// ESLint 9 moved the junit formatter to its own package
+		eslintMajor, _ := strconv.Atoi(majorVersionPattern.FindString(ls[labels.DepsNode].Dependencies["eslint"]))
+		if eslintMajor >= 9 && ls[labels.DepsNode].Dependencies["eslint-formatter-junit"] == "" {
+			steps = append(steps, nodeAddPackageStep(ls, "eslint-formatter-junit"))
+		}
+		steps = append(steps,
+			createLintResultsDirStep,
+			lintStep("Run ESLint",
+				nodeExecCommand(ls, "eslint . --format junit --output-file "+lintResultsPath+"/eslint.xml")))
+	}
+	// Prettier can't write JUnit XML, the step fails if files aren't formatted
+	if ls[labels.LintPrettier].Valid {
+		steps = append(steps, lintStep("Check formatting with Prettier", nodeExecCommand(ls, "prettier --check .")))
+	}
+	if ls[labels.LintESLint].Valid {
+		steps = append(steps, storeLintResultsStep)
+	}
+
+	job := &Job{
+		Job: config.Job{
+			Name:             "lint-node",
+			Comment:          "Run linters and check formatting",
+			WorkingDirectory: workingDirectory(ls[labels.DepsNode]),
+			Steps:            steps,
+		},
+		Type:   LintJob,
+		Labels: validLabelKeys(ls, labels.DepsNode, labels.PackageManagerBun, labels.LintESLint, labels.LintPrettier),
+	}
+	if ls[labels.PackageManagerBun].Valid {
+		job.DockerImages = []string{bunDockerImage}
+	} else {
+		job.Executor = "node/default"
+		job.Orbs = map[string]string{"node": nodeOrb}
+	}
+	return job
+}
+
+func goLintJob(ls labels.LabelSet) *Job {
+	steps := goInitialSteps(ls)
+	steps = append(steps,
+		lintStep("Install golangci-lint",
+			"curl -sSfL https://raw.githubusercontent.com/golangci/golangci-lint/"+golangciLintVersion+"/install.sh | "+
+				"sh -s -- -b $(go env GOPATH)/bin "+golangciLintVersion),
+		createLintResultsDirStep,
+		lintStep("Run golangci-lint",
+			"golangci-lint run --out-format=colored-line-number:stdout,junit-xml:"+lintResultsPath+"/golangci-lint.xml"),
+		storeLintResultsStep)
+
+	return &Job{
+		Job: config.Job{
+			Name:             "lint-go",
+			Comment:          "Run golangci-lint",
+			DockerImages:     []string{goDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsGo]),
+			Steps:            steps,
+		},
+		Type:   LintJob,
+		Labels: validLabelKeys(ls, labels.DepsGo, labels.LintGolangci),
+	}
+}
+
+func pythonLintJob(ls labels.LabelSet) *Job {
+	// the linters don't need the project's dependencies, only themselves
+	var packages []string
+	var lintSteps []config.Step
+	if ls[labels.LintRuff].Valid {
+		packages = append(packages, "ruff=="+ruffVersion)
+		lintSteps = append(lintSteps, lintStep("Run ruff",
+			"ruff check --output-format=junit --output-file="+lintResultsPath+"/ruff.xml ."))
+	}
+	if ls[labels.LintFlake8].Valid {
+		packages = append(packages, "flake8=="+flake8Version, "flake8-formatter-junit-xml=="+flake8JUnitFormatterVersion)
+		lintSteps = append(lintSteps, lintStep("Run flake8",
+			"flake8 --format=junit-xml --output-file="+lintResultsPath+"/flake8.xml"))
+	}
+	// black can't write JUnit XML, the step fails if files aren't formatted
+	if ls[labels.LintBlack].Valid {
+		packages = append(packages, "black=="+blackVersion)
+		lintSteps = append(lintSteps, lintStep("Check formatting with black", "black --check ."))
+	}
+
+	steps := []config.Step{
+		checkoutStep(ls[labels.DepsPython]),
+		lintStep("Install linters", "pip install "+strings.Join(packages, " ")),
+		createLintResultsDirStep,
+	}
+	steps = append(steps, lintSteps...)
+	steps = append(steps, storeLintResultsStep)
+
+	return &Job{
+		Job: config.Job{
+			Name:             "lint-python",
+			Comment:          "Run linters and check formatting",
+			DockerImages:     []string{pythonImageVersion(ls)},
+			WorkingDirectory: workingDirectory(ls[labels.DepsPython]),
+			Steps:            steps,
+		},
+		Type:   LintJob,
+		Labels: validLabelKeys(ls, labels.DepsPython, labels.LintRuff, labels.LintFlake8, labels.LintBlack),
+	}
+}
+
+func rubyLintJob(ls labels.LabelSet) *Job {
+	steps := rubyInitialSteps(ls)
+	steps = append(steps,
+		createLintResultsDirStep,
+		lintStep("Run RuboCop",
+			"bundle exec rubocop --format progress --format junit --out "+lintResultsPath+"/rubocop.xml"),
+		storeLintResultsStep)
+
+	return &Job{
+		Job: config.Job{
+			Name:             "lint-ruby",
+			Comment:          "Run RuboCop",
+			DockerImages:     []string{rubyImageVersion(ls)},
+			WorkingDirectory: workingDirectory(ls[labels.DepsRuby]),
+			Steps:            steps,
+		},
+		Type:   LintJob,
+		Orbs:   map[string]string{"ruby": rubyOrb},
+		Labels: validLabelKeys(ls, labels.DepsRuby, labels.LintRubocop),
+	}
+}
+
+func phpLintJob(ls labels.LabelSet) *Job {
+	steps := initialPhpSteps(ls)
+	steps = append(steps, createLintResultsDirStep)
+	if ls[labels.LintPhpcs].Valid {
+		steps = append(steps, lintStep("Run PHP_CodeSniffer",
+			"./vendor/bin/phpcs --report-full --report-junit="+lintResultsPath+"/phpcs.xml"))
+	}
+	if ls[labels.LintPhpstan].Valid {
+		steps = append(steps, lintStep("Run PHPStan",
+			"./vendor/bin/phpstan analyse --no-progress --error-format=junit > "+lintResultsPath+"/phpstan.xml"))
+	}
+	steps = append(steps, storeLintResultsStep)
+
+	return &Job{
+		Job: config.Job{
+			Name:             "lint-php",
+			Comment:          "Run static analysis",
+			DockerImages:     []string{phpImageVersion(ls[labels.DepsPhp].Dependencies["php"])},
+			WorkingDirectory: workingDirectory(ls[labels.DepsPhp]),
+			Steps:            steps,
+		},
+		Type:   LintJob,
+		Orbs:   map[string]string{"php": "circleci/php@1"},
+		Labels: validLabelKeys(ls, labels.DepsPhp, labels.LintPhpcs, labels.LintPhpstan),
+	}
+}
+
+// rustLintJob fails on warnings and unformatted code, as neither clippy nor rustfmt can
+// write JUnit XML
+func rustLintJob(ls labels.LabelSet) *Job {
+	steps := rustInitialSteps(ls)
+	if ls[labels.LintRustfmt].Valid {
+		steps = append(steps, lintStep("Check formatting with rustfmt", "cargo fmt --check"))
+	}
+	if ls[labels.LintClippy].Valid {
+		steps = append(steps, lintStep("Run clippy", "cargo clippy --all-targets -- -D warnings"))
+	}
+
+	return &Job{
+		Job: config.Job{
+			Name:             "lint-rust",
+			Comment:          "Run clippy and check formatting",
+			DockerImages:     []string{rustDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsRust]),
+			Steps:            steps,
+		},
+		Type:   LintJob,
+		Labels: validLabelKeys(ls, labels.DepsRust, labels.LintClippy, labels.LintRustfmt),
+	}
+}
+
+// javaLintJob only restores the cache of Maven or Gradle dependencies, which the test job saves
+func javaLintJob(ls labels.LabelSet) *Job {
+	var tasks []string
+	command := "mvn"
+	if ls[labels.ToolGradle].Valid {
+		command = "./gradlew"
+		if ls[labels.LintCheckstyle].Valid {
+			tasks = append(tasks, "checkstyleMain", "checkstyleTest")
+		}
+		if ls[labels.LintSpotless].Valid {
+			tasks = append(tasks, "spotlessCheck")
+		}
+	} else {
+		if ls[labels.LintCheckstyle].Valid {
+			tasks = append(tasks, "checkstyle:check")
+		}
+		if ls[labels.LintSpotless].Valid {
+			tasks = append(tasks, "spotless:check")
+		}
+	}
+
+	return &Job{
+		Job: config.Job{
+			Name:             "lint-java",
+			Comment:          "Run Checkstyle and check formatting",
+			DockerImages:     []string{javaDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsJava]),
+			Steps: []config.Step{
+				checkoutStep(ls[labels.DepsJava]),
+				{
+					Type:    config.Run,
+					Name:    "Calculate cache key",
+					Command: javaCacheKeyCommand,
+				},
+				{
+					Type:     config.RestoreCache,
+					CacheKey: javaCacheKey,
+				},
+				lintStep("Run linters", command+" "+strings.Join(tasks, " ")),
+			},
+		},
+		Type:   LintJob,
+		Labels: validLabelKeys(ls, labels.DepsJava, labels.ToolGradle, labels.LintCheckstyle, labels.LintSpotless),
+	}
+}
+
+// GenerateLintJobs returns a lint job for each stack with a linter configured
+func GenerateLintJobs(ls labels.LabelSet) (jobs []*Job) {
+	nodeLinted := ls[labels.LintESLint].Valid || ls[labels.LintPrettier].Valid
+	if ls[labels.DepsNode].Valid && nodeLinted && !ls[labels.DepsDeno].Valid {
+		jobs = append(jobs, nodeLintJob(ls))
+	}
+	if ls[labels.LintGolangci].Valid {
+		jobs = append(jobs, goLintJob(ls))
+	}
+	if ls[labels.LintRuff].Valid || ls[labels.LintFlake8].Valid || ls[labels.LintBlack].Valid {
+		jobs = append(jobs, pythonLintJob(ls))
+	}
+	if ls[labels.LintRubocop].Valid {
+		jobs = append(jobs, rubyLintJob(ls))
+	}
+	if ls[labels.LintPhpcs].Valid || ls[labels.LintPhpstan].Valid {
+		jobs = append(jobs, phpLintJob(ls))
+	}
+	if ls[labels.LintClippy].Valid || ls[labels.LintRustfmt].Valid {
+		jobs = append(jobs, rustLintJob(ls))
+	}
+	if ls[labels.LintCheckstyle].Valid || ls[labels.LintSpotless].Valid {
+		jobs = append(jobs, javaLintJob(ls))
+	}
+	return jobs
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerateLintJobs(t *testing.T) {
+	valid := func(key string, basePath string) labels.Label {
+		return labels.Label{Key: key, Valid: true, LabelData: labels.LabelData{BasePath: basePath}}
+	}
+	tests := []struct {
+		name          string
+		labels        []labels.Label
+		expectedName  string
+		expectedSteps []config.Step
+	}{
+		{
+			name:         "ruff and black",
+			labels:       []labels.Label{valid(labels.DepsPython, "."), valid(labels.LintRuff, "."), valid(labels.LintBlack, ".")},
+			expectedName: "lint-python",
+			expectedSteps: []config.Step{
+				{Type: config.Checkout},
+				{Type: config.Run, Name: "Install linters", Command: "pip install ruff==0.1.9 black==23.12.1"},
+				createLintResultsDirStep,
+				{
+					Type:    config.Run,
+					Name:    "Run ruff",
+					Command: "ruff check --output-format=junit --output-file=lint-results/ruff.xml .",
+				},
+				{Type: config.Run, Name: "Check formatting with black", Command: "black --check ."},
+				{Type: config.StoreTestResults, Path: "lint-results"},
+			},
+		},
+		{
+			name: "Checkstyle and Spotless with gradle",
+			labels: []labels.Label{
+				valid(labels.DepsJava, "."), valid(labels.ToolGradle, "."),
+				valid(labels.LintCheckstyle, "."), valid(labels.LintSpotless, "."),
+			},
+			expectedName: "lint-java",
+			expectedSteps: []config.Step{
+				{Type: config.Checkout},
+				{Type: config.Run, Name: "Calculate cache key", Command: javaCacheKeyCommand},
+				{Type: config.RestoreCache, CacheKey: javaCacheKey},
+				{
+					Type:    config.Run,
+					Name:    "Run linters",
+					Command: "./gradlew checkstyleMain checkstyleTest spotlessCheck",
+				},
+			},
+		},
+		{
+			name:         "Checkstyle with maven",
+			labels:       []labels.Label{valid(labels.DepsJava, "."), valid(labels.LintCheckstyle, ".")},
+			expectedName: "lint-java",
+			expectedSteps: []config.Step{
+				{Type: config.Checkout},
+				{Type: config.Run, Name: "Calculate cache key", Command: javaCacheKeyCommand},
+				{Type: config.RestoreCache, CacheKey: javaCacheKey},
+				{Type: config.Run, Name: "Run linters", Command: "mvn checkstyle:check"},
+			},
+		},
+		{
+			name:         "rustfmt only",
+			labels:       []labels.Label{valid(labels.DepsRust, "."), valid(labels.LintRustfmt, ".")},
+			expectedName: "lint-rust",
+			expectedSteps: []config.Step{
+				{Type: config.Checkout},
+				{Type: config.RestoreCache, CacheKey: cargoCacheKey},
+				{Type: config.Run, Name: "Check formatting with rustfmt", Command: "cargo fmt --check"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := make(labels.LabelSet)
+			for _, label := range tt.labels {
+				ls[label.Key] = label
+			}
+			jobs := GenerateLintJobs(ls)
+			if len(jobs) != 1 {
+				t.Fatalf("got %d jobs, expected 1", len(jobs))
+			}
+			if jobs[0].Name != tt.expectedName || jobs[0].Type != LintJob {
+				t.Errorf("got job %s of type %d, expected %s", jobs[0].Name, jobs[0].Type, tt.expectedName)
+			}
+			if d := cmp.Diff(tt.expectedSteps, jobs[0].Steps); d != "" {
+				t.Errorf("steps mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package internal
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/CircleCI-Public/circleci-config/config"
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
@@ -14,12 +16,99 @@ func npmTaskDefined(ls labels.LabelSet, task string) bool {
 }
 
 func nodePackageManager(ls labels.LabelSet) string {
+	if ls[labels.PackageManagerBun].Valid {
+		return "bun"
+	}
+	if ls[labels.PackageManagerPnpm].Valid {
+		return "pnpm"
+	}
 	if ls[labels.PackageManagerYarn].Valid {
 		return "yarn"
 	}
 	return "npm"
 }
 
+// nodeExecCommand returns the command running bin from node_modules/.bin
+func nodeExecCommand(ls labels.LabelSet, bin string) string {
+	switch nodePackageManager(ls) {
+	case "pnpm":
+		return "pnpm exec " + bin
+	case "yarn":
+		return "yarn " + bin
+	case "bun":
+		return "bunx " + bin
+	}
+	return "npx " + bin
+}
+
+// nodeWorkspaceCommand returns the command running task in every package of a monorepo,
+// with its monorepo tool if it has one, or "" if it's not a monorepo
+func nodeWorkspaceCommand(ls labels.LabelSet, task string) string {
+	switch {
+	case ls[labels.ToolTurborepo].Valid:
+		return nodeExecCommand(ls, fmt.Sprintf("turbo run %s --cache-dir=%s", task, turboCachePath))
+	case ls[labels.ToolNx].Valid:
+		return nodeExecCommand(ls, "nx run-many -t "+task)
+	case ls[labels.ToolLerna].Valid:
+		return nodeExecCommand(ls, "lerna run "+task)
+	case !ls[labels.WorkspacesNode].Valid:
+		return ""
+	}
+
+	switch nodePackageManager(ls) {
+	case "pnpm":
+		if task == "test" {
+			return "pnpm -r test"
+		}
+		return "pnpm -r run " + task
+	case "yarn":
+		if ls[labels.PackageManagerYarn].Version == "berry" {
+			return "yarn workspaces foreach --all run " + task
+		}
+		return "yarn workspaces run " + task
+	}
+	return fmt.Sprintf("npm run %s --workspaces --if-present", task)
+}
+
+const turboCachePath = ".turbo"
+
+// the cache is saved for every commit, and restored from the latest one
+var restoreTurboCacheStep = config.Step{
+	Type:     config.RestoreCache,
+	CacheKey: "turbo-",
+}
+
+var saveTurboCacheStep = config.Step{
+	Type:     config.SaveCache,
+	CacheKey: "turbo-{{ .Revision }}",
+	Path:     turboCachePath,
+}
+
+// nodeWorkspaceSteps returns the steps running task in every package of a monorepo,
+// restoring and saving the Turborepo cache
+func nodeWorkspaceSteps(ls labels.LabelSet, name string, task string) []config.Step {
+	step := config.Step{
+		Type:    config.Run,
+		Name:    name,
+		Command: nodeWorkspaceCommand(ls, task),
+	}
+	if !ls[labels.ToolTurborepo].Valid {
+		return []config.Step{step}
+	}
+	return []config.Step{restoreTurboCacheStep, step, saveTurboCacheStep}
+}
+
+// nodeLabels are the labels node jobs are generated from
+var nodeLabels = []string{
+	labels.DepsNode,
+	labels.PackageManagerYarn,
+	labels.PackageManagerPnpm,
+	labels.WorkspacesNode,
+	labels.ToolTurborepo,
+	labels.ToolNx,
+	labels.ToolLerna,
+}
+
 func nodeRunCommand(ls labels.LabelSet, task string) string {
 	if task == "test" {
 		// don't fail for default test value for new node projects
@@ -41,9 +130,13 @@ func nodeInitialSteps(ls labels.LabelSet) []config.Step {
 	}
 	if !ls[labels.DepsNode].HasLockFile {
 		installParams = config.OrbCommandParameters{
-			"cache-path":          "~/project/node_modules",
+			"cache-path":          path.Join(defaultCheckoutDir, ls[labels.DepsNode].BasePath, "node_modules"),
 			"override-ci-command": fmt.Sprintf("%s install", nodePackageManager(ls)),
 		}
+		// unlike npm and yarn, pnpm is only installed by the orb for pkg-manager pnpm
+		if ls[labels.PackageManagerPnpm].Valid {
+			installParams["pkg-manager"] = "pnpm"
+		}
 	}
 
 	steps = append(steps,
@@ -57,9 +150,80 @@ func nodeInitialSteps(ls labels.LabelSet) []config.Step {
 	return steps
 }
 
+// nodeAddPackageStep adds pkg to the dependencies, for the reporters writing JUnit XML
+func nodeAddPackageStep(ls labels.LabelSet, pkg string) config.Step {
+	command := "npm install " + pkg
+	switch nodePackageManager(ls) {
+	case "pnpm":
+		command = "pnpm add -D " + pkg
+		if ls[labels.WorkspacesNode].Valid {
+			command += " --workspace-root"
+		}
+	case "yarn":
+		command = fmt.Sprintf("yarn add %s --ignore-workspace-root-check", pkg)
+		if ls[labels.PackageManagerYarn].Version == "berry" {
+			// yarn-berry doesn't support --ignore-workspace-root-check and it's not needed in this case
+			command = "yarn add " + pkg
+		}
+	}
+	return config.Step{
+		Type:    config.Run,
+		Command: command,
+	}
+}
+
+const nodeTestResultsPath = "test-results"
+
+// nodeJUnitTestSteps run the tests with Vitest, Mocha or AVA, with their reporters writing
+// JUnit XML to nodeTestResultsPath, or return nil if none of them is used
+func nodeJUnitTestSteps(ls labels.LabelSet) []config.Step {
+	var steps []config.Step
+	switch {
+	case ls[labels.TestVitest].Valid:
+		steps = append(steps, config.Step{
+			Type: config.Run,
+			Name: "Run tests with Vitest",
+			Command: nodeExecCommand(ls, "vitest run --reporter=default --reporter=junit "+
+				"--outputFile.junit="+nodeTestResultsPath+"/junit.xml"),
+		})
+	case ls[labels.TestMocha].Valid:
+		if ls[labels.DepsNode].Dependencies["mocha-junit-reporter"] == "" {
+			steps = append(steps, nodeAddPackageStep(ls, "mocha-junit-reporter"))
+		}
+		steps = append(steps, config.Step{
+			Type: config.Run,
+			Name: "Run tests with Mocha",
+			Command: nodeExecCommand(ls, "mocha --reporter mocha-junit-reporter "+
+				"--reporter-options mochaFile="+nodeTestResultsPath+"/junit.xml"),
+		})
+	case ls[labels.TestAva].Valid:
+		// AVA has no JUnit reporter, so its TAP output is converted
+		steps = append(steps, config.Step{
+			Type: config.Run,
+			Name: "Run tests with AVA",
+			Command: fmt.Sprintf("mkdir -p %s && %s | npx --yes tap-xunit > %s/junit.xml",
+				nodeTestResultsPath, nodeExecCommand(ls, "ava --tap"), nodeTestResultsPath),
+		})
+	default:
+		return nil
+	}
+	return append(steps, config.Step{
+		Type: config.StoreTestResults,
+		Path: nodeTestResultsPath,
+	})
+}
+
+func isE2ETestScript(script string) bool {
+	return strings.HasPrefix(script, "playwright test") || strings.HasPrefix(script, "cypress run")
+}
+
 func nodeTestSteps(ls labels.LabelSet) []config.Step {
 	hasJestLabel := ls[labels.TestJest].Valid
 
+	if nodeWorkspaceCommand(ls, "test") != "" {
+		return nodeWorkspaceSteps(ls, "Run tests", "test")
+	}
+
 	if npmTaskDefined(ls, "test:ci") {
 		return []config.Step{{
 			Name:    "Run tests",
@@ -68,7 +232,14 @@ func nodeTestSteps(ls labels.LabelSet) []config.Step {
 		}}
 	}
 
-	if npmTaskDefined(ls, "test") {
+	if !hasJestLabel {
+		if steps := nodeJUnitTestSteps(ls); steps != nil {
+			return steps
+		}
+	}
+
+	// a test script running only E2E tests is left to the E2E test jobs
+	if npmTaskDefined(ls, "test") && !isE2ETestScript(ls[labels.DepsNode].Tasks["test"]) {
 		if hasJestLabel {
 			return []config.Step{{
 				Name: "Run tests",
@@ -111,23 +282,7 @@ func nodeTestJob(ls labels.LabelSet) *Job {
 	steps := nodeInitialSteps(ls)
 
 	if hasJestLabel && ls[labels.DepsNode].Dependencies["jest-junit"] == "" {
-		if nodePackageManager(ls) == "yarn" {
-			command := "yarn add jest-junit --ignore-workspace-root-check"
-
-			if ls[labels.PackageManagerYarn].Version == "berry" {
-				// yarn-berry doesn't support --ignore-workspace-root-check and it's not needed in this case
-				command = "yarn add jest-junit"
-			}
-			steps = append(steps, config.Step{
-				Type:    config.Run,
-				Command: command,
-			})
-		} else {
-			steps = append(steps, config.Step{
-				Type:    config.Run,
-				Command: "npm install jest-junit",
-			})
-		}
+		steps = append(steps, nodeAddPackageStep(ls, "jest-junit"))
 	}
 
 	testSteps := nodeTestSteps(ls)
@@ -160,69 +315,104 @@ func nodeTestJob(ls labels.LabelSet) *Job {
 	}
 
 	return &Job{
-		Job:  job,
-		Type: TestJob,
-		Orbs: map[string]string{"node": nodeOrb},
+		Job:    job,
+		Type:   TestJob,
+		Orbs:   map[string]string{"node": nodeOrb},
+		Labels: validLabelKeys(ls, append(nodeLabels, labels.TestJest, labels.TestVitest, labels.TestMocha, labels.TestAva)...),
 	}
 }
 
-func nodeBuildJob(ls labels.LabelSet) *Job {
-	// Possible build task names in order of preference
-	buildTasks := []string{
-		"build:ci",
-		"build:production",
-		"build:prod",
-		"build",
-		"build:development",
-		"build:dev",
-	}
+// nodeBuildTasks are the possible build task names, in order of preference
+var nodeBuildTasks = []string{
+	"build:ci",
+	"build:production",
+	"build:prod",
+	"build",
+	"build:development",
+	"build:dev",
+}
 
-	steps := nodeInitialSteps(ls)
+var copyNodeArtifactsStep = config.Step{
+	Type:    config.Run,
+	Comment: "Copy output to artifacts dir",
+	Name:    "Copy artifacts",
+	Command: "cp -R build dist public .output .next .docusaurus ~/artifacts 2>/dev/null || true",
+}
 
-	for _, task := range buildTasks {
-		if npmTaskDefined(ls, task) {
+// copyNodeWorkspaceArtifactsStep copies the output of the packages of a monorepo, like
+// apps/web/dist, keeping their paths
+var copyNodeWorkspaceArtifactsStep = config.Step{
+	Type:    config.Run,
+	Comment: "Copy the output of every package to artifacts dir",
+	Name:    "Copy artifacts",
+	Command: "cp -R --parents */*/build */*/dist */*/.next ~/artifacts 2>/dev/null || true",
+}
 
-			steps = append(steps, []config.Step{
-				{
-					Type:    config.Run,
-					Command: nodeRunCommand(ls, task),
-				},
-				createArtifactsDirStep,
-				{
-					Type:    config.Run,
-					Comment: "Copy output to artifacts dir",
-					Name:    "Copy artifacts",
-					Command: "cp -R build dist public .output .next .docusaurus ~/artifacts 2>/dev/null || true",
-				},
-				storeArtifactsStep("node-build")}...)
-
-			return &Job{
-				Job: config.Job{
-					Name:             "build-node",
-					Comment:          "Build node project",
-					Executor:         "node/default",
-					WorkingDirectory: workingDirectory(ls[labels.DepsNode]),
-					Steps:            steps,
-				},
-				Type: ArtifactJob,
-				Orbs: map[string]string{"node": nodeOrb},
-			}
+// nodeBuildTask returns the first of nodeBuildTasks defined in package.json, if any
+func nodeBuildTask(ls labels.LabelSet) string {
+	for _, task := range nodeBuildTasks {
+		if npmTaskDefined(ls, task) {
+			return task
 		}
 	}
+	return ""
+}
+
+func nodeBuildJob(ls labels.LabelSet) *Job {
+	task := nodeBuildTask(ls)
+	if task == "" {
+		return nil
+	}
 
-	return nil
+	steps := nodeInitialSteps(ls)
+	copyArtifactsStep := copyNodeArtifactsStep
+	if task == "build" && nodeWorkspaceCommand(ls, task) != "" {
+		steps = append(steps, nodeWorkspaceSteps(ls, "Build", task)...)
+		copyArtifactsStep = copyNodeWorkspaceArtifactsStep
+	} else {
+		steps = append(steps, config.Step{
+			Type:    config.Run,
+			Command: nodeRunCommand(ls, task),
+		})
+	}
+	steps = append(steps,
+		createArtifactsDirStep,
+		copyArtifactsStep,
+		storeArtifactsStep("node-build"))
+
+	return &Job{
+		Job: config.Job{
+			Name:             "build-node",
+			Comment:          "Build node project",
+			Executor:         "node/default",
+			WorkingDirectory: workingDirectory(ls[labels.DepsNode]),
+			Steps:            steps,
+		},
+		Type:   ArtifactJob,
+		Orbs:   map[string]string{"node": nodeOrb},
+		Labels: validLabelKeys(ls, nodeLabels...),
+	}
 }
 
 func GenerateNodeJobs(ls labels.LabelSet) (jobs []*Job) {
 	if !ls[labels.DepsNode].Valid {
 		return nil
 	}
+	// Deno runs the package.json of its projects itself
+	if ls[labels.DepsDeno].Valid && ls[labels.DepsDeno].BasePath == ls[labels.DepsNode].BasePath {
+		return nil
+	}
+	if ls[labels.PackageManagerBun].Valid {
+		return generateBunJobs(ls)
+	}
 
 	testJob := nodeTestJob(ls)
 	if testJob != nil {
 		jobs = append(jobs, testJob)
 	}
 
+	jobs = append(jobs, nodeE2ETestJobs(ls)...)
+
 	buildJob := nodeBuildJob(ls)
 	if buildJob != nil {
 		jobs = append(jobs, buildJob)
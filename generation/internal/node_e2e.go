@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"regexp"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// the Playwright image comes with the browsers of its version, so it should match the
+// version of @playwright/test
+const defaultPlaywrightVersion = "1.41.2"
+
+// comes with Chrome and Firefox
+const cypressDockerImage = "cimg/node:20.11-browsers"
+
+// e2eTestResultsPath is where the E2E jobs write JUnit XML, apart from the screenshots and
+// videos they store as artifacts
+const e2eTestResultsPath = "e2e-results"
+
+var playwrightVersionPattern = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+func playwrightDockerImage(ls labels.LabelSet) string {
+	version := playwrightVersionPattern.FindString(ls[labels.TestPlaywright].Version)
+	if version == "" {
+		version = defaultPlaywrightVersion
+	}
+	return "mcr.microsoft.com/playwright:v" + version + "-jammy"
+}
+
+func playwrightTestJob(ls labels.LabelSet) *Job {
+	steps := nodeInitialSteps(ls)
+	steps = append(steps,
+		config.Step{
+			// a no-op, unless the installed version doesn't match the image's
+			Type:    config.Run,
+			Name:    "Install browsers",
+			Command: nodeExecCommand(ls, "playwright install"),
+		},
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run E2E tests with Playwright",
+			Command: nodeExecCommand(ls, "playwright test --reporter=line,junit"),
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: e2eTestResultsPath,
+		},
+		config.Step{
+			// screenshots, videos and traces of the failed tests
+			Type:        config.StoreArtifacts,
+			Path:        "test-results",
+			Destination: "playwright",
+		})
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-playwright",
+			Comment:          "Run E2E tests in browsers with Playwright",
+			DockerImages:     []string{playwrightDockerImage(ls)},
+			WorkingDirectory: workingDirectory(ls[labels.DepsNode]),
+			Steps:            steps,
+			Environment: map[string]string{
+				"PLAYWRIGHT_JUNIT_OUTPUT_NAME": e2eTestResultsPath + "/junit.xml",
+			},
+		},
+		Type:   TestJob,
+		Orbs:   map[string]string{"node": nodeOrb},
+		Labels: validLabelKeys(ls, append(nodeLabels, labels.TestPlaywright)...),
+	}
+}
+
+func cypressTestJob(ls labels.LabelSet) *Job {
+	steps := nodeInitialSteps(ls)
+	steps = append(steps,
+		config.Step{
+			Type: config.Run,
+			Name: "Run E2E tests with Cypress",
+			Command: nodeExecCommand(ls, "cypress run --reporter junit "+
+				`--reporter-options "mochaFile=`+e2eTestResultsPath+`/cypress-[hash].xml"`),
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: e2eTestResultsPath,
+		},
+		config.Step{
+			Type:        config.StoreArtifacts,
+			Path:        "cypress/screenshots",
+			Destination: "cypress/screenshots",
+		},
+		config.Step{
+			Type:        config.StoreArtifacts,
+			Path:        "cypress/videos",
+			Destination: "cypress/videos",
+		})
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-cypress",
+			Comment:          "Run E2E tests in browsers with Cypress",
+			DockerImages:     []string{cypressDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsNode]),
+			Steps:            steps,
+		},
+		Type:   TestJob,
+		Orbs:   map[string]string{"node": nodeOrb},
+		Labels: validLabelKeys(ls, append(nodeLabels, labels.TestCypress)...),
+	}
+}
+
+// nodeE2ETestJobs run the browser tests apart from the unit tests, as they need browsers
+func nodeE2ETestJobs(ls labels.LabelSet) (jobs []*Job) {
+	if ls[labels.TestPlaywright].Valid {
+		jobs = append(jobs, playwrightTestJob(ls))
+	}
+	if ls[labels.TestCypress].Valid {
+		jobs = append(jobs, cypressTestJob(ls))
+	}
+	return jobs
+}
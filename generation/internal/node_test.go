@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_nodeWorkspaceCommand(t *testing.T) {
+	valid := func(key string, version string) labels.Label {
+		return labels.Label{Key: key, Valid: true, LabelData: labels.LabelData{BasePath: ".", Version: version}}
+	}
+	tests := []struct {
+		name     string
+		labels   []labels.Label
+		expected string
+	}{
+		{
+			name:     "not a monorepo",
+			labels:   []labels.Label{valid(labels.DepsNode, "")},
+			expected: "",
+		},
+		{
+			name:     "npm workspaces",
+			labels:   []labels.Label{valid(labels.DepsNode, ""), valid(labels.WorkspacesNode, "")},
+			expected: "npm run test --workspaces --if-present",
+		},
+		{
+			name: "yarn classic workspaces",
+			labels: []labels.Label{
+				valid(labels.DepsNode, ""), valid(labels.WorkspacesNode, ""), valid(labels.PackageManagerYarn, "classic"),
+			},
+			expected: "yarn workspaces run test",
+		},
+		{
+			name: "yarn berry workspaces",
+			labels: []labels.Label{
+				valid(labels.DepsNode, ""), valid(labels.WorkspacesNode, ""), valid(labels.PackageManagerYarn, "berry"),
+			},
+			expected: "yarn workspaces foreach --all run test",
+		},
+		{
+			name: "pnpm workspaces",
+			labels: []labels.Label{
+				valid(labels.DepsNode, ""), valid(labels.WorkspacesNode, ""), valid(labels.PackageManagerPnpm, ""),
+			},
+			expected: "pnpm -r test",
+		},
+		{
+			name:     "Nx with npm",
+			labels:   []labels.Label{valid(labels.DepsNode, ""), valid(labels.ToolNx, "")},
+			expected: "npx nx run-many -t test",
+		},
+		{
+			name: "Lerna with yarn workspaces",
+			labels: []labels.Label{
+				valid(labels.DepsNode, ""), valid(labels.WorkspacesNode, ""), valid(labels.PackageManagerYarn, "classic"),
+				valid(labels.ToolLerna, ""),
+			},
+			expected: "yarn lerna run test",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := make(labels.LabelSet)
+			for _, label := range tt.labels {
+				ls[label.Key] = label
+			}
+			if got := nodeWorkspaceCommand(ls, "test"); got != tt.expected {
+				t.Errorf("got %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_nodeTestSteps_junitReporters(t *testing.T) {
+	tests := []struct {
+		name          string
+		labels        []labels.Label
+		tasks         map[string]string
+		expectedSteps []config.Step
+	}{
+		{
+			name:   "mocha with yarn, without mocha-junit-reporter",
+			labels: []labels.Label{{Key: labels.TestMocha}, {Key: labels.PackageManagerYarn, LabelData: labels.LabelData{Version: "berry"}}},
+			expectedSteps: []config.Step{
+				{Type: config.Run, Command: "yarn add mocha-junit-reporter"},
+				{
+					Type:    config.Run,
+					Name:    "Run tests with Mocha",
+					Command: "yarn mocha --reporter mocha-junit-reporter --reporter-options mochaFile=test-results/junit.xml",
+				},
+				{Type: config.StoreTestResults, Path: "test-results"},
+			},
+		},
+		{
+			name:   "ava with pnpm",
+			labels: []labels.Label{{Key: labels.TestAva}, {Key: labels.PackageManagerPnpm}},
+			expectedSteps: []config.Step{
+				{
+					Type:    config.Run,
+					Name:    "Run tests with AVA",
+					Command: "mkdir -p test-results && pnpm exec ava --tap | npx --yes tap-xunit > test-results/junit.xml",
+				},
+				{Type: config.StoreTestResults, Path: "test-results"},
+			},
+		},
+		{
+			name:   "test:ci script over vitest",
+			labels: []labels.Label{{Key: labels.TestVitest}},
+			tasks:  map[string]string{"test:ci": "vitest run --coverage"},
+			expectedSteps: []config.Step{
+				{Type: config.Run, Name: "Run tests", Command: "npm run test:ci"},
+			},
+		},
+		{
+			name:          "only playwright",
+			labels:        []labels.Label{{Key: labels.TestPlaywright}},
+			tasks:         map[string]string{"test": "playwright test"},
+			expectedSteps: []config.Step{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := labels.LabelSet{
+				labels.DepsNode: labels.Label{
+					Key:       labels.DepsNode,
+					Valid:     true,
+					LabelData: labels.LabelData{BasePath: ".", Tasks: tt.tasks},
+				},
+			}
+			for _, label := range tt.labels {
+				label.Valid = true
+				ls[label.Key] = label
+			}
+			if d := cmp.Diff(tt.expectedSteps, nodeTestSteps(ls)); d != "" {
+				t.Errorf("steps mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func Test_nodeInitialSteps_cachePath(t *testing.T) {
+	for basePath, expected := range map[string]string{
+		".":        "~/project/node_modules",
+		"frontend": "~/project/frontend/node_modules",
+	} {
+		ls := labels.LabelSet{
+			labels.DepsNode: {Key: labels.DepsNode, Valid: true, LabelData: labels.LabelData{BasePath: basePath}},
+		}
+		got := nodeInitialSteps(ls)[1].Parameters["cache-path"]
+		if got != expected {
+			t.Errorf("got cache-path %q for %s, expected %q", got, basePath, expected)
+		}
+	}
+}
+
+func Test_nodeInitialSteps_pnpmWithoutLockFile(t *testing.T) {
+	ls := labels.LabelSet{
+		labels.DepsNode:           {Key: labels.DepsNode, Valid: true, LabelData: labels.LabelData{BasePath: "."}},
+		labels.PackageManagerPnpm: {Key: labels.PackageManagerPnpm, Valid: true, LabelData: labels.LabelData{BasePath: "."}},
+	}
+	expected := config.OrbCommandParameters{
+		"cache-path":          "~/project/node_modules",
+		"override-ci-command": "pnpm install",
+		"pkg-manager":         "pnpm",
+	}
+	if d := cmp.Diff(expected, nodeInitialSteps(ls)[1].Parameters); d != "" {
+		t.Errorf("install-packages parameters mismatch (-want +got):\n%s", d)
+	}
+}
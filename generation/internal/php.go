@@ -69,5 +69,6 @@ func phpTestJob(ls labels.LabelSet) *Job {
 		Orbs: map[string]string{
 			"php": "circleci/php@1",
 		},
+		Labels: validLabelKeys(ls, labels.DepsPhp),
 	}
 }
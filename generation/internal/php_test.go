@@ -49,8 +49,9 @@ func TestGeneratePHPJobs(t *testing.T) {
 								Command: "./vendor/bin/phpunit",
 							},
 						}},
-					Type: TestJob,
-					Orbs: map[string]string{"php": "circleci/php@1"},
+					Type:   TestJob,
+					Orbs:   map[string]string{"php": "circleci/php@1"},
+					Labels: []string{labels.DepsPhp},
 				},
 			},
 		},
@@ -86,8 +87,9 @@ func TestGeneratePHPJobs(t *testing.T) {
 								Command: "./vendor/bin/phpunit",
 							},
 						}},
-					Type: TestJob,
-					Orbs: map[string]string{"php": "circleci/php@1"},
+					Type:   TestJob,
+					Orbs:   map[string]string{"php": "circleci/php@1"},
+					Labels: []string{labels.DepsPhp},
 				},
 			},
 		},
@@ -55,6 +55,13 @@ func pythonTestJob(ls labels.LabelSet) *Job {
 		Orbs: map[string]string{
 			"python": pythonOrb,
 		},
+		Labels: validLabelKeys(ls,
+			labels.DepsPython,
+			labels.FileSetupPy,
+			labels.PackageManagerPipenv,
+			labels.PackageManagerPoetry,
+			labels.FileManagePy,
+			labels.TestTox),
 	}
 }
 
@@ -85,6 +92,7 @@ func pythonBuildJob(ls labels.LabelSet) *Job {
 		Orbs: map[string]string{
 			"python": pythonOrb,
 		},
+		Labels: validLabelKeys(ls, labels.DepsPython, labels.FileSetupPy),
 	}
 }
 
@@ -90,6 +90,7 @@ func rspecJob(ls labels.LabelSet) *Job {
 		Orbs: map[string]string{
 			"ruby": rubyOrb,
 		},
+		Labels: validLabelKeys(ls, labels.DepsRuby, labels.PackageManagerGemspec),
 	}
 }
 
@@ -114,6 +115,7 @@ func rakeJob(ls labels.LabelSet) *Job {
 		Orbs: map[string]string{
 			"ruby": rubyOrb,
 		},
+		Labels: validLabelKeys(ls, labels.DepsRuby, labels.PackageManagerGemspec),
 	}
 }
 
@@ -138,6 +140,7 @@ func railsTestJob(ls labels.LabelSet) *Job {
 		Orbs: map[string]string{
 			"ruby": rubyOrb,
 		},
+		Labels: validLabelKeys(ls, labels.DepsRuby, labels.PackageManagerGemspec),
 	}
 }
 
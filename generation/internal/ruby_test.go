@@ -150,8 +150,9 @@ func TestGenerateRubyJobs(t *testing.T) {
 								Command: "bundle exec rake test",
 							},
 						}},
-					Type: TestJob,
-					Orbs: map[string]string{"ruby": "circleci/ruby@2.0.1"},
+					Type:   TestJob,
+					Orbs:   map[string]string{"ruby": "circleci/ruby@2.0.1"},
+					Labels: []string{labels.DepsRuby},
 				},
 			},
 		},
@@ -187,8 +188,9 @@ func TestGenerateRubyJobs(t *testing.T) {
 								Command: "bundle exec rails test",
 							},
 						}},
-					Type: TestJob,
-					Orbs: map[string]string{"ruby": "circleci/ruby@2.0.1"},
+					Type:   TestJob,
+					Orbs:   map[string]string{"ruby": "circleci/ruby@2.0.1"},
+					Labels: []string{labels.DepsRuby},
 				},
 			},
 		},
@@ -225,8 +227,9 @@ func TestGenerateRubyJobs(t *testing.T) {
 								Command: "bundle exec rspec",
 							},
 						}},
-					Type: TestJob,
-					Orbs: map[string]string{"ruby": "circleci/ruby@2.0.1"},
+					Type:   TestJob,
+					Orbs:   map[string]string{"ruby": "circleci/ruby@2.0.1"},
+					Labels: []string{labels.DepsRuby},
 				},
 			},
 		},
@@ -265,8 +268,9 @@ func TestGenerateRubyJobs(t *testing.T) {
 								Command: "bundle exec rspec",
 							},
 						}},
-					Type: TestJob,
-					Orbs: map[string]string{"ruby": "circleci/ruby@2.0.1"},
+					Type:   TestJob,
+					Orbs:   map[string]string{"ruby": "circleci/ruby@2.0.1"},
+					Labels: []string{labels.DepsRuby, labels.PackageManagerGemspec},
 				},
 			},
 		},
@@ -37,7 +37,8 @@ func rustTestJob(ls labels.LabelSet) *Job {
 			WorkingDirectory: workingDirectory(ls[labels.DepsRust]),
 			Steps:            steps,
 		},
-		Type: TestJob,
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsRust),
 	}
 }
 
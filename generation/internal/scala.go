@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// the launcher downloads the sbt version in project/build.properties
+const sbtLauncherVersion = "1.9.9"
+
+const sbtCacheKeyCommand = `find . -name '*.sbt' -o -name 'build.properties' | \
+        sort | xargs cat > /tmp/CIRCLECI_CACHE_KEY`
+const sbtCacheKey = `sbt-{{ checksum "/tmp/CIRCLECI_CACHE_KEY" }}`
+
+func scalaInitialSteps(ls labels.LabelSet) []config.Step {
+	return []config.Step{
+		checkoutStep(ls[labels.DepsScala]),
+		{
+			Type:    config.Run,
+			Name:    "Calculate cache key",
+			Command: sbtCacheKeyCommand,
+		},
+		{
+			Type:     config.RestoreCache,
+			CacheKey: sbtCacheKey,
+		},
+		{
+			Type: config.Run,
+			Name: "Install sbt",
+			Command: fmt.Sprintf("curl -fsSL https://github.com/sbt/sbt/releases/download/v%s/sbt-%s.tgz | "+
+				"sudo tar xz -C /usr/local --strip-components=1", sbtLauncherVersion, sbtLauncherVersion),
+		},
+	}
+}
+
+var saveSbtCacheStep = config.Step{
+	Type:     config.SaveCache,
+	CacheKey: sbtCacheKey,
+	Path:     "~/.cache/coursier",
+}
+
+func scalaTestJob(ls labels.LabelSet) *Job {
+	steps := scalaInitialSteps(ls)
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: "sbt test",
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: "target/test-reports",
+		},
+		saveSbtCacheStep)
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-scala",
+			Comment:          "Install sbt and run tests",
+			DockerImages:     []string{javaDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsScala]),
+			Steps:            steps,
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsScala),
+	}
+}
+
+func scalaAssemblyJob(ls labels.LabelSet) *Job {
+	steps := scalaInitialSteps(ls)
+	steps = append(steps,
+		createArtifactsDirStep,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Build a fat jar",
+			Command: fmt.Sprintf("sbt assembly && find target -name '*-assembly-*.jar' -exec cp {} %s \\;", artifactsPath),
+		},
+		storeArtifactsStep("jars"),
+		saveSbtCacheStep)
+
+	return &Job{
+		Job: config.Job{
+			Name:             "build-scala-assembly",
+			Comment:          "Build a fat jar with sbt-assembly and store it as an artifact",
+			DockerImages:     []string{javaDockerImage},
+			WorkingDirectory: workingDirectory(ls[labels.DepsScala]),
+			Steps:            steps,
+		},
+		Type:   ArtifactJob,
+		Labels: validLabelKeys(ls, labels.DepsScala, labels.ArtifactSbtAssembly),
+	}
+}
+
+func GenerateScalaJobs(ls labels.LabelSet) (jobs []*Job) {
+	if !ls[labels.DepsScala].Valid {
+		return nil
+	}
+
+	jobs = append(jobs, scalaTestJob(ls))
+	if ls[labels.ArtifactSbtAssembly].Valid {
+		jobs = append(jobs, scalaAssemblyJob(ls))
+	}
+	return jobs
+}
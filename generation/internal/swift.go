@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/CircleCI-Public/circleci-config/config"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+const xcodeFallbackVersion = "15.1.0"
+const macOSResourceClass = "macos.m1.medium.gen1"
+const iOSSimulatorDestination = "platform=iOS Simulator,name=iPhone 15"
+const mobileTestResultsPath = "test-results"
+
+func xcodeVersion(ls labels.LabelSet) string {
+	if version := ls[labels.ToolXcode].Version; version != "" {
+		return version
+	}
+	return xcodeFallbackVersion
+}
+
+func cocoapodsSteps(ls labels.LabelSet) []config.Step {
+	podsLabel := ls[labels.PackageManagerCocoapods]
+	if !podsLabel.Valid || podsLabel.BasePath != ls[labels.ToolXcode].BasePath {
+		return nil
+	}
+	installPods := config.Step{Type: config.Run, Name: "Install CocoaPods", Command: "pod install"}
+	if !podsLabel.HasLockFile {
+		return []config.Step{installPods}
+	}
+
+	const podsCacheKey = `pods-{{ checksum "Podfile.lock" }}`
+	return []config.Step{
+		{Type: config.RestoreCache, CacheKey: podsCacheKey},
+		installPods,
+		{Type: config.SaveCache, CacheKey: podsCacheKey, Path: "Pods"},
+	}
+}
+
+// xcodeTestCommand runs the tests with fastlane scan if there is a Fastfile, and else with
+// xcodebuild, on an iOS simulator
+func xcodeTestCommand(ls labels.LabelSet) string {
+	if ls[labels.ToolFastlane].Valid {
+		command := fmt.Sprintf("fastlane scan --output_types junit --output_directory %s", mobileTestResultsPath)
+		if ls[labels.DepsRuby].Valid {
+			command = "bundle exec " + command
+		}
+		return command
+	}
+
+	xcodeLabel := ls[labels.ToolXcode]
+	bundle := "-project " + xcodeLabel.Tasks["project"]
+	if workspace := xcodeLabel.Tasks["workspace"]; workspace != "" {
+		bundle = "-workspace " + workspace
+	}
+	return fmt.Sprintf("set -o pipefail && xcodebuild test %s -scheme %s -destination '%s' | "+
+		"xcpretty --report junit --output %s/junit.xml",
+		bundle, xcodeLabel.Tasks["scheme"], iOSSimulatorDestination, mobileTestResultsPath)
+}
+
+func xcodeTestJob(ls labels.LabelSet) *Job {
+	xcodeLabel := ls[labels.ToolXcode]
+	steps := []config.Step{checkoutStep(xcodeLabel)}
+	if ls[labels.ToolFastlane].Valid && ls[labels.DepsRuby].Valid {
+		steps = append(steps, config.Step{Type: config.Run, Name: "Install gems", Command: "bundle install"})
+	}
+	steps = append(steps, cocoapodsSteps(ls)...)
+	steps = append(steps,
+		config.Step{
+			Type:    config.Run,
+			Name:    "Run tests",
+			Command: xcodeTestCommand(ls),
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: mobileTestResultsPath,
+		})
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-xcode",
+			Comment:          "Build and test the app on a simulator",
+			MacOSXcode:       xcodeVersion(ls),
+			ResourceClass:    macOSResourceClass,
+			WorkingDirectory: workingDirectory(xcodeLabel),
+			Steps:            steps,
+		},
+		Type: TestJob,
+		Labels: validLabelKeys(ls,
+			labels.ToolXcode, labels.PackageManagerCocoapods, labels.ToolFastlane),
+	}
+}
+
+func swiftTestJob(ls labels.LabelSet) *Job {
+	depsLabel := ls[labels.DepsSwift]
+	steps := []config.Step{checkoutStep(depsLabel)}
+
+	const swiftCacheKey = `swiftpm-{{ checksum "Package.resolved" }}`
+	if depsLabel.HasLockFile {
+		steps = append(steps, config.Step{Type: config.RestoreCache, CacheKey: swiftCacheKey})
+	}
+	steps = append(steps, config.Step{
+		Type:    config.Run,
+		Name:    "Build",
+		Command: "swift build",
+	})
+	if depsLabel.HasLockFile {
+		steps = append(steps, config.Step{Type: config.SaveCache, CacheKey: swiftCacheKey, Path: ".build"})
+	}
+	steps = append(steps,
+		config.Step{
+			Type: config.Run,
+			Name: "Run tests",
+			Command: fmt.Sprintf("mkdir -p %s && swift test --parallel --xunit-output %s/results.xml",
+				mobileTestResultsPath, mobileTestResultsPath),
+		},
+		config.Step{
+			Type: config.StoreTestResults,
+			Path: mobileTestResultsPath,
+		})
+
+	return &Job{
+		Job: config.Job{
+			Name:             "test-swift",
+			Comment:          "Build the package and run its tests",
+			MacOSXcode:       xcodeVersion(ls),
+			ResourceClass:    macOSResourceClass,
+			WorkingDirectory: workingDirectory(depsLabel),
+			Steps:            steps,
+		},
+		Type:   TestJob,
+		Labels: validLabelKeys(ls, labels.DepsSwift),
+	}
+}
+
+func GenerateSwiftJobs(ls labels.LabelSet) (jobs []*Job) {
+	if ls[labels.ToolXcode].Valid {
+		return append(jobs, xcodeTestJob(ls))
+	}
+	if ls[labels.DepsSwift].Valid {
+		return append(jobs, swiftTestJob(ls))
+	}
+	return nil
+}
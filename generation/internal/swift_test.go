@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+func Test_xcodeTestCommand(t *testing.T) {
+	xcodeLabel := labels.Label{
+		Key:   labels.ToolXcode,
+		Valid: true,
+		LabelData: labels.LabelData{
+			BasePath: ".",
+			Tasks:    map[string]string{"project": "Tool.xcodeproj", "scheme": "Tool"},
+		},
+	}
+	tests := []struct {
+		name            string
+		labels          labels.LabelSet
+		expectedCommand string
+	}{
+		{
+			name:   "xcode project",
+			labels: labels.LabelSet{labels.ToolXcode: xcodeLabel},
+			expectedCommand: "set -o pipefail && xcodebuild test -project Tool.xcodeproj -scheme Tool " +
+				"-destination 'platform=iOS Simulator,name=iPhone 15' | " +
+				"xcpretty --report junit --output test-results/junit.xml",
+		},
+		{
+			name: "fastlane",
+			labels: labels.LabelSet{
+				labels.ToolXcode:    xcodeLabel,
+				labels.ToolFastlane: labels.Label{Key: labels.ToolFastlane, Valid: true},
+			},
+			expectedCommand: "fastlane scan --output_types junit --output_directory test-results",
+		},
+		{
+			name: "fastlane in the Gemfile",
+			labels: labels.LabelSet{
+				labels.ToolXcode:    xcodeLabel,
+				labels.ToolFastlane: labels.Label{Key: labels.ToolFastlane, Valid: true},
+				labels.DepsRuby:     labels.Label{Key: labels.DepsRuby, Valid: true},
+			},
+			expectedCommand: "bundle exec fastlane scan --output_types junit --output_directory test-results",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := xcodeTestCommand(tt.labels); got != tt.expectedCommand {
+				t.Errorf("\ngot      %q\nexpected %q", got, tt.expectedCommand)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyAndroidRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "android app module",
+			files: map[string]string{
+				"android/gradlew":              "",
+				"android/settings.gradle":      "include ':app'",
+				"android/build.gradle":         "buildscript {}",
+				"android/app/build.gradle":     "apply plugin: \"com.android.application\"\n",
+				"android/library/build.gradle": "plugins { id 'com.android.library' }\n",
+			},
+			expectedLabels: []labels.Label{
+				{Key: labels.DepsJava, LabelData: labels.LabelData{BasePath: "android"}},
+				{Key: labels.ToolGradle},
+				{Key: labels.DepsAndroid, LabelData: labels.LabelData{BasePath: "android"}},
+			},
+		},
+		{
+			name: "kotlin multiplatform module with an android app",
+			files: map[string]string{
+				"gradlew":                     "",
+				"shared/build.gradle.kts":     "plugins {\n  kotlin(\"multiplatform\")\n  id(\"com.android.library\")\n}\n",
+				"androidApp/build.gradle.kts": "plugins {\n  alias(libs.plugins.androidApplication)\n}\n",
+			},
+			expectedLabels: []labels.Label{
+				{Key: labels.DepsJava, LabelData: labels.LabelData{BasePath: "."}},
+				{Key: labels.ToolGradle},
+				{Key: labels.DepsAndroid, LabelData: labels.LabelData{BasePath: "."}},
+				{Key: labels.DepsKotlinMultiplatform, LabelData: labels.LabelData{BasePath: "."}},
+			},
+		},
+		{
+			name: "android module without gradlew",
+			files: map[string]string{
+				"app/build.gradle.kts": "plugins { id(\"com.android.application\") }\n",
+			},
+			expectedLabels: []labels.Label{
+				{Key: labels.DepsAndroid, LabelData: labels.LabelData{BasePath: "app"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
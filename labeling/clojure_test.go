@@ -0,0 +1,57 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyClojureRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "leiningen project with a main namespace",
+			files: map[string]string{
+				"project.clj": `(defproject shop "0.1.0-SNAPSHOT"
+  :dependencies [[org.clojure/clojure "1.11.1"]]
+  :main ^:skip-aot shop.core
+  :profiles {:uberjar {:aot :all}})`,
+			},
+			expectedLabels: []labels.Label{
+				{Key: labels.DepsClojure, LabelData: labels.LabelData{BasePath: "."}},
+				{Key: labels.PackageManagerLein, LabelData: labels.LabelData{BasePath: "."}},
+				{Key: labels.ArtifactLeinUberjar, LabelData: labels.LabelData{BasePath: "."}},
+			},
+		},
+		{
+			name: "tools.deps project",
+			files: map[string]string{
+				"lib/deps.edn": `{:aliases {:test {:exec-fn cognitect.test-runner.api/test}}}`,
+			},
+			expectedLabels: []labels.Label{
+				{Key: labels.DepsClojure, LabelData: labels.LabelData{BasePath: "lib"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package codebase
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Limits on the uncompressed size of archives, so that decompression bombs can't exhaust
+// memory. Files bigger than maxArchiveFileSize are listed, but can't be read.
+const maxArchiveFileSize = 16 << 20
+const maxArchiveSize = 256 << 20
+
+var ErrArchiveTooLarge = errors.New("archive too large")
+var ErrFileTooLarge = errors.New("file too large")
+
+// ArchiveCodebase is a Codebase for the files in a .zip or .tar.gz archive, read into
+// memory. Like LocalCodebase, it lists up to maxFiles files, skipping dirs deeper than
+// defaultMaxDepth, and finds the shallowest matching file first. Dirs are not listed.
+// If all files are in a single top-level dir, like in the archives GitHub generates,
+// paths are relative to that dir.
+type ArchiveCodebase struct {
+	fileSet  []string
+	contents map[string][]byte // nil for files bigger than maxArchiveFileSize
+	sizes    map[string]int64
+}
+
+// IsArchive returns true if path has the extension of an archive OpenArchiveCodebase reads
+func IsArchive(path string) bool {
+	return archiveType(path) != ""
+}
+
+func archiveType(p string) string {
+	p = strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(p, ".zip"):
+		return "zip"
+	case strings.HasSuffix(p, ".tar.gz"), strings.HasSuffix(p, ".tgz"):
+		return "tar.gz"
+	}
+	return ""
+}
+
+// OpenArchiveCodebase reads the .zip, .tar.gz or .tgz archive at path.
+// It returns an error if the archive has paths outside of its root, like "../x" or "/x",
+// or if the files in it add up to more than maxArchiveSize.
+func OpenArchiveCodebase(path string) (*ArchiveCodebase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch archiveType(path) {
+	case "zip":
+		stat, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return NewZipCodebase(f, stat.Size())
+	case "tar.gz":
+		return NewTarGzCodebase(f)
+	}
+	return nil, fmt.Errorf("%s is not a .zip, .tar.gz or .tgz archive", path)
+}
+
+// NewZipCodebase reads a zip archive of the given size, see OpenArchiveCodebase
+func NewZipCodebase(r io.ReaderAt, size int64) (*ArchiveCodebase, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newArchiveBuilder()
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !f.Mode().IsRegular() {
+			continue
+		}
+		err = b.add(f.Name, int64(f.UncompressedSize64), func() (io.ReadCloser, error) { return f.Open() })
+		if errors.Is(err, errEnoughFiles) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.build(), nil
+}
+
+// NewTarGzCodebase reads a gzip-compressed tar archive, see OpenArchiveCodebase
+func NewTarGzCodebase(r io.Reader) (*ArchiveCodebase, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	b := newArchiveBuilder()
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			// dirs, links and devices
+			continue
+		}
+		err = b.add(h.Name, h.Size, func() (io.ReadCloser, error) { return io.NopCloser(tr), nil })
+		if errors.Is(err, errEnoughFiles) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.build(), nil
+}
+
+var errEnoughFiles = errors.New("enough files")
+
+type archiveBuilder struct {
+	files     []string
+	contents  map[string][]byte
+	sizes     map[string]int64
+	totalSize int64
+}
+
+func newArchiveBuilder() *archiveBuilder {
+	return &archiveBuilder{contents: make(map[string][]byte), sizes: make(map[string]int64)}
+}
+
+// add reads a file of the given size from the archive, unless it's too deep. It returns
+// errEnoughFiles once maxFiles files have been added.
+func (b *archiveBuilder) add(name string, size int64, open func() (io.ReadCloser, error)) error {
+	p, err := archivePath(name)
+	if err != nil {
+		return err
+	}
+	// the top-level dir may be stripped later, so allow for one more level
+	if pathDepth(path.Dir(p)) > defaultMaxDepth+1 {
+		return nil
+	}
+
+	rc, err := open()
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+	defer rc.Close()
+	contents, err := io.ReadAll(io.LimitReader(rc, maxArchiveFileSize+1))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	b.totalSize += int64(len(contents))
+	if b.totalSize > maxArchiveSize {
+		return ErrArchiveTooLarge
+	}
+	if len(contents) > maxArchiveFileSize {
+		contents = nil
+	}
+
+	b.files = append(b.files, p)
+	b.contents[p] = contents
+	b.sizes[p] = size
+	if len(b.files) >= maxFiles {
+		return errEnoughFiles
+	}
+	return nil
+}
+
+// archivePath returns name as a clean relative path, or an error if it points outside of
+// the archive root
+func archivePath(name string) (string, error) {
+	p := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") || hasDriveLetter(p) {
+		return "", fmt.Errorf("unsafe path in archive: %s", name)
+	}
+	return p, nil
+}
+
+func hasDriveLetter(p string) bool {
+	return len(p) >= 2 && p[1] == ':'
+}
+
+// build strips a top-level dir all files share and drops the files that are too deep
+func (b *archiveBuilder) build() *ArchiveCodebase {
+	prefix := commonTopLevelDir(b.files)
+
+	c := &ArchiveCodebase{contents: make(map[string][]byte), sizes: make(map[string]int64)}
+	for _, f := range b.files {
+		p := strings.TrimPrefix(f, prefix)
+		if pathDepth(path.Dir(p)) > defaultMaxDepth {
+			continue
+		}
+		c.fileSet = append(c.fileSet, p)
+		c.contents[p] = b.contents[f]
+		c.sizes[p] = b.sizes[f]
+	}
+	// like on disk, the order of files in the archive doesn't matter
+	sort.Strings(c.fileSet)
+	sortByDepth(c.fileSet)
+	return c
+}
+
+// commonTopLevelDir returns "dir/" if all files are under dir, or "" otherwise
+func commonTopLevelDir(files []string) string {
+	prefix := ""
+	for _, f := range files {
+		i := strings.Index(f, "/")
+		if i < 0 {
+			return ""
+		}
+		if prefix == "" {
+			prefix = f[:i+1]
+		} else if prefix != f[:i+1] {
+			return ""
+		}
+	}
+	return prefix
+}
+
+func (c *ArchiveCodebase) FindFileMatching(
+	predicate func(string) bool,
+	glob ...string,
+) (string, error) {
+	return findFileMatching(c.Walk, predicate, glob...)
+}
+
+func (c *ArchiveCodebase) FindAll(glob ...string) *Files {
+	return findAll(c.Walk, glob...)
+}
+
+func (c *ArchiveCodebase) Walk(fn func(path string) error) error {
+	return walkPaths(c.fileSet, fn)
+}
+
+func (c *ArchiveCodebase) Stat(filePath string) (FileInfo, error) {
+	return statInFiles(filePath, func(p string) (int64, bool) {
+		size, ok := c.sizes[p]
+		return size, ok
+	}, c.Walk)
+}
+
+func (c *ArchiveCodebase) FindFile(glob ...string) (path string, err error) {
+	return c.FindFileMatching(func(string) bool { return true }, glob...)
+}
+
+func (c *ArchiveCodebase) ReadFile(filePath string) (contents []byte, err error) {
+	contents, ok := c.contents[path.Clean(filePath)]
+	if !ok {
+		return nil, NotFoundError
+	}
+	if contents == nil {
+		return nil, fmt.Errorf("%s: %w", filePath, ErrFileTooLarge)
+	}
+	return contents, nil
+}
@@ -0,0 +1,158 @@
+package codebase
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type archiveFile struct {
+	name     string
+	contents string
+}
+
+func zipArchive(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(f.contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		h := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.contents)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpenArchiveCodebase(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []archiveFile
+		expectErr bool
+		check     func(t *testing.T, c Codebase)
+	}{
+		{
+			name: "shallowest file first",
+			files: []archiveFile{
+				{"web/package.json", `{"name": "web"}`},
+				{"package.json", `{"name": "root"}`},
+				{"a/b/c/d/go.mod", "too deep"},
+			},
+			check: func(t *testing.T, c Codebase) {
+				found, err := c.FindFile("package.json")
+				if err != nil || found != "package.json" {
+					t.Errorf("got %q (error %v), expected package.json", found, err)
+				}
+				contents, _ := c.ReadFile("web/package.json")
+				if string(contents) != `{"name": "web"}` {
+					t.Errorf("got contents %q", contents)
+				}
+				if found, err = c.FindFile("go.mod"); !errors.Is(err, NotFoundError) {
+					t.Errorf("got %q (error %v), expected files deeper than the max depth to be skipped", found, err)
+				}
+			},
+		}, {
+			name: "single top-level dir is stripped",
+			files: []archiveFile{
+				{"repo-main/go.mod", "module x"},
+				{"repo-main/cmd/main.go", "package main"},
+			},
+			check: func(t *testing.T, c Codebase) {
+				found, err := c.FindFile("*.go")
+				if err != nil || found != "cmd/main.go" {
+					t.Errorf("got %q (error %v), expected cmd/main.go", found, err)
+				}
+				if _, err = c.ReadFile("go.mod"); err != nil {
+					t.Errorf("got error %v reading go.mod", err)
+				}
+			},
+		}, {
+			name:      "path outside of the archive",
+			files:     []archiveFile{{"go.mod", ""}, {"../../etc/passwd", "x"}},
+			expectErr: true,
+		}, {
+			name:      "absolute path",
+			files:     []archiveFile{{"/etc/passwd", "x"}},
+			expectErr: true,
+		}, {
+			name: "files bigger than the limit can't be read",
+			files: []archiveFile{
+				{"big.json", strings.Repeat(" ", maxArchiveFileSize+1)},
+				{"go.mod", ""},
+			},
+			check: func(t *testing.T, c Codebase) {
+				if _, err := c.FindFile("big.json"); err != nil {
+					t.Errorf("got error %v, expected big.json to be listed", err)
+				}
+				if _, err := c.ReadFile("big.json"); !errors.Is(err, ErrFileTooLarge) {
+					t.Errorf("got error %v, expected ErrFileTooLarge", err)
+				}
+			},
+		}, {
+			name: "decompression bomb",
+			files: func() []archiveFile {
+				var files []archiveFile
+				for i := 0; i <= maxArchiveSize/maxArchiveFileSize; i++ {
+					files = append(files, archiveFile{strings.Repeat("x", i+1), strings.Repeat(" ", maxArchiveFileSize)})
+				}
+				return files
+			}(),
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		for ext, archive := range map[string]func(*testing.T, []archiveFile) []byte{
+			".zip":    zipArchive,
+			".tar.gz": tarGzArchive,
+		} {
+			t.Run(tt.name+ext, func(t *testing.T) {
+				p := filepath.Join(t.TempDir(), "archive"+ext)
+				if err := os.WriteFile(p, archive(t, tt.files), 0644); err != nil {
+					t.Fatal(err)
+				}
+				c, err := OpenArchiveCodebase(p)
+				if (err != nil) != tt.expectErr {
+					t.Fatalf("OpenArchiveCodebase() error %v, expectErr %v", err, tt.expectErr)
+				}
+				if tt.check != nil {
+					tt.check(t, c)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package codebase
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"sync"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultMaxCachedFileSize = 1 << 20
+const defaultMaxCachedBytes = 64 << 20
+
+// CachingCodebase is a Codebase that caches the files read from another Codebase, and the
+// documents ReadJSON, ReadTOML, ReadYAML and ReadXML parse from them, so that rules reading the
+// same files don't read and parse them again.
+// Files bigger than the max cached file size, or that would take the cache over its max
+// bytes, are read every time.
+type CachingCodebase struct {
+	Codebase
+
+	maxFileSize int64
+	maxBytes    int64
+
+	mu        sync.Mutex
+	files     map[string]cachedFile
+	documents map[documentKey]cachedDocument
+	stats     CacheStats
+}
+
+// CacheStats counts how many reads and parsed documents came from the cache (hits), and how
+// many didn't (misses)
+type CacheStats struct {
+	FileHits       int
+	FileMisses     int
+	DocumentHits   int
+	DocumentMisses int
+	Bytes          int64 // size of the cached files
+}
+
+type cachedFile struct {
+	contents []byte
+	err      error
+}
+
+type documentKey struct {
+	path   string
+	format string
+	typ    reflect.Type
+}
+
+type cachedDocument struct {
+	value interface{}
+	err   error
+}
+
+// CachingCodebaseOption changes the limits of NewCachingCodebase
+type CachingCodebaseOption func(*CachingCodebase)
+
+// WithMaxCachedFileSize only caches files of up to maxFileSize bytes. The default is 1MiB.
+func WithMaxCachedFileSize(maxFileSize int64) CachingCodebaseOption {
+	return func(c *CachingCodebase) { c.maxFileSize = maxFileSize }
+}
+
+// WithMaxCachedBytes caches files up to a total of maxBytes bytes. The default is 64MiB.
+func WithMaxCachedBytes(maxBytes int64) CachingCodebaseOption {
+	return func(c *CachingCodebase) { c.maxBytes = maxBytes }
+}
+
+// NewCachingCodebase returns a CachingCodebase for c. If c is a CachingCodebase already,
+// and no options are given, it returns c.
+func NewCachingCodebase(c Codebase, opts ...CachingCodebaseOption) *CachingCodebase {
+	if cached, ok := c.(*CachingCodebase); ok && len(opts) == 0 {
+		return cached
+	}
+	cached := &CachingCodebase{
+		Codebase:    c,
+		maxFileSize: defaultMaxCachedFileSize,
+		maxBytes:    defaultMaxCachedBytes,
+		files:       make(map[string]cachedFile),
+		documents:   make(map[documentKey]cachedDocument),
+	}
+	for _, opt := range opts {
+		opt(cached)
+	}
+	return cached
+}
+
+func (c *CachingCodebase) ReadFile(path string) (contents []byte, err error) {
+	c.mu.Lock()
+	if f, ok := c.files[path]; ok {
+		c.stats.FileHits++
+		c.mu.Unlock()
+		return f.contents, f.err
+	}
+	c.stats.FileMisses++
+	c.mu.Unlock()
+
+	contents, err = c.Codebase.ReadFile(path)
+
+	size := int64(len(contents))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.files[path]; !ok && size <= c.maxFileSize && c.stats.Bytes+size <= c.maxBytes {
+		c.files[path] = cachedFile{contents: contents, err: err}
+		c.stats.Bytes += size
+	}
+	return contents, err
+}
+
+// Stats returns the hits and misses of the cache so far
+func (c *CachingCodebase) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// document returns the document parse returns for key, parsing it only the first time
+// unless its file is not cached
+func (c *CachingCodebase) document(key documentKey, parse func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if d, ok := c.documents[key]; ok {
+		c.stats.DocumentHits++
+		c.mu.Unlock()
+		return d.value, d.err
+	}
+	c.stats.DocumentMisses++
+	c.mu.Unlock()
+
+	value, err := parse()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, fileCached := c.files[key.path]; fileCached {
+		c.documents[key] = cachedDocument{value: value, err: err}
+	}
+	return value, err
+}
+
+// ReadJSON reads the JSON file at path into a T. If c is a CachingCodebase, the result is
+// memoized, so it must not be modified.
+func ReadJSON[T any](c Codebase, path string) (T, error) {
+	return readDocument[T](c, path, "json", json.Unmarshal)
+}
+
+// ReadTOML is like ReadJSON, for TOML files
+func ReadTOML[T any](c Codebase, path string) (T, error) {
+	return readDocument[T](c, path, "toml", toml.Unmarshal)
+}
+
+// ReadYAML is like ReadJSON, for YAML files
+func ReadYAML[T any](c Codebase, path string) (T, error) {
+	return readDocument[T](c, path, "yaml", yaml.Unmarshal)
+}
+
+// ReadXML is like ReadJSON, for XML files, like .csproj files
+func ReadXML[T any](c Codebase, path string) (T, error) {
+	return readDocument[T](c, path, "xml", xml.Unmarshal)
+}
+
+func readDocument[T any](c Codebase, path string, format string, unmarshal func([]byte, interface{}) error) (T, error) {
+	parse := func() (interface{}, error) {
+		var v T
+		contents, err := c.ReadFile(path)
+		if err != nil {
+			return v, err
+		}
+		err = unmarshal(contents, &v)
+		return v, err
+	}
+
+	cached, ok := c.(*CachingCodebase)
+	if !ok {
+		v, err := parse()
+		return v.(T), err
+	}
+	key := documentKey{path: path, format: format, typ: reflect.TypeOf((*T)(nil)).Elem()}
+	v, err := cached.document(key, parse)
+	return v.(T), err
+}
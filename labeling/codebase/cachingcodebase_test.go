@@ -0,0 +1,115 @@
+package codebase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// countingCodebase counts the reads of each file
+type countingCodebase struct {
+	MapCodebase
+	reads map[string]int
+}
+
+func (c countingCodebase) ReadFile(path string) ([]byte, error) {
+	c.reads[path]++
+	return c.MapCodebase.ReadFile(path)
+}
+
+func TestCachingCodebase_ReadFile(t *testing.T) {
+	files := countingCodebase{
+		MapCodebase: MapCodebase{
+			"small.txt": "small",
+			"big.txt":   strings.Repeat("x", 100),
+			"other.txt": "other",
+		},
+		reads: make(map[string]int),
+	}
+	c := NewCachingCodebase(files, WithMaxCachedFileSize(50), WithMaxCachedBytes(8))
+
+	for i := 0; i < 3; i++ {
+		for _, p := range []string{"small.txt", "big.txt", "other.txt", "missing.txt"} {
+			_, _ = c.ReadFile(p)
+		}
+	}
+	if _, err := c.ReadFile("missing.txt"); !errors.Is(err, NotFoundError) {
+		t.Errorf("got error %v, expected NotFoundError to be cached too", err)
+	}
+
+	expectedReads := map[string]int{
+		"small.txt":   1, // cached
+		"big.txt":     3, // bigger than the max file size
+		"other.txt":   3, // over the max bytes, once small.txt is cached
+		"missing.txt": 1,
+	}
+	if d := cmp.Diff(expectedReads, files.reads); d != "" {
+		t.Errorf("reads mismatch (-want +got):\n%s", d)
+	}
+	expectedStats := CacheStats{FileHits: 5, FileMisses: 8, Bytes: 5}
+	if d := cmp.Diff(expectedStats, c.Stats()); d != "" {
+		t.Errorf("stats mismatch (-want +got):\n%s", d)
+	}
+
+	if NewCachingCodebase(c) != c {
+		t.Errorf("expected a CachingCodebase not to be wrapped again")
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	type document struct {
+		Name    string            `json:"name" toml:"name" yaml:"name" xml:"name,attr"`
+		Scripts map[string]string `json:"scripts" toml:"scripts" yaml:"scripts" xml:"-"`
+	}
+	expected := document{Name: "x", Scripts: map[string]string{"test": "jest"}}
+	files := MapCodebase{
+		"package.json":   `{"name": "x", "scripts": {"test": "jest"}}`,
+		"pyproject.toml": "name = \"x\"\n[scripts]\ntest = \"jest\"\n",
+		"config.yml":     "name: x\nscripts:\n  test: jest\n",
+		"project.xml":    `<project name="x"></project>`,
+		"invalid.json":   "{",
+	}
+
+	for _, c := range []Codebase{files, NewCachingCodebase(files)} {
+		got, err := ReadJSON[document](c, "package.json")
+		if d := cmp.Diff(expected, got); err != nil || d != "" {
+			t.Errorf("ReadJSON() error %v, mismatch (-want +got):\n%s", err, d)
+		}
+		got, err = ReadTOML[document](c, "pyproject.toml")
+		if d := cmp.Diff(expected, got); err != nil || d != "" {
+			t.Errorf("ReadTOML() error %v, mismatch (-want +got):\n%s", err, d)
+		}
+		got, err = ReadYAML[document](c, "config.yml")
+		if d := cmp.Diff(expected, got); err != nil || d != "" {
+			t.Errorf("ReadYAML() error %v, mismatch (-want +got):\n%s", err, d)
+		}
+		got, err = ReadXML[document](c, "project.xml")
+		if d := cmp.Diff(document{Name: "x"}, got); err != nil || d != "" {
+			t.Errorf("ReadXML() error %v, mismatch (-want +got):\n%s", err, d)
+		}
+		if _, err = ReadJSON[document](c, "invalid.json"); err == nil {
+			t.Errorf("got no error, expected invalid.json not to parse")
+		}
+		if _, err = ReadJSON[document](c, "missing.json"); !errors.Is(err, NotFoundError) {
+			t.Errorf("got error %v, expected NotFoundError", err)
+		}
+	}
+
+	t.Run("documents are memoized by type", func(t *testing.T) {
+		c := NewCachingCodebase(files)
+		first, _ := ReadJSON[document](c, "package.json")
+		second, _ := ReadJSON[document](c, "package.json")
+		first.Scripts["test"] = "changed"
+		if second.Scripts["test"] != "changed" {
+			t.Errorf("expected the second read to return the memoized document")
+		}
+		_, _ = ReadJSON[map[string]interface{}](c, "package.json")
+
+		expectedStats := CacheStats{FileHits: 1, FileMisses: 1, DocumentHits: 1, DocumentMisses: 2, Bytes: 42}
+		if d := cmp.Diff(expectedStats, c.Stats()); d != "" {
+			t.Errorf("stats mismatch (-want +got):\n%s", d)
+		}
+	})
+}
@@ -1,14 +1,71 @@
 package codebase
 
 // Codebase interface that allows finding files in a codebase and reading file contents
+// Rules are applied concurrently, so implementations must be safe for concurrent use.
 type Codebase interface {
 	// FindFileMatching returns the path of the first file it finds matching a `glob`
 	// and for which predicate returns true.
-	// Note by go standard library doesn't understand globs with double asterisks,
-	// like "/**/project.clj"
+	// Globs without a slash, like "*.go", match file names in any dir. Others match paths
+	// from the root of the codebase, and can use "**" for any number of dirs, like
+	// "**/src/test/**/*.java". Braces give alternatives, like "*.{yml,yaml}", and globs
+	// starting with "!" leave out the files they match, like "!vendor/**".
 	FindFileMatching(predicate func(path string) bool, glob ...string) (path string, err error)
 	// FindFile is like FindFileMatching, but with a constantly true predicate, so it always
 	// returns the first file that matches glob
 	FindFile(glob ...string) (path string, err error)
+	// FindAll returns all the files matching a glob, in the order FindFile looks at them
+	FindAll(glob ...string) *Files
+	// Walk calls fn with the path of every file in the codebase, shallowest first, like
+	// FindFile looks at them. It stops at the first error fn returns, and returns it,
+	// unless it's fs.SkipAll.
+	Walk(fn func(path string) error) error
+	// Stat returns the FileInfo of a file, or NotFoundError
+	Stat(path string) (FileInfo, error)
 	ReadFile(path string) (contents []byte, err error)
 }
+
+// FileInfo describes a file in a Codebase
+type FileInfo struct {
+	Path  string
+	IsDir bool
+	Size  int64 // in bytes, 0 for dirs
+}
+
+// Files iterates over the paths found by Codebase.FindAll:
+//
+//	files := c.FindAll("*.gemspec")
+//	for files.Next() {
+//		fmt.Println(files.Path())
+//	}
+//	if err := files.Err(); err != nil {
+//		...
+//	}
+type Files struct {
+	paths []string
+	next  int
+	err   error
+}
+
+// Next moves to the next path, and returns false when there are no more paths
+func (f *Files) Next() bool {
+	if f.next >= len(f.paths) {
+		return false
+	}
+	f.next++
+	return true
+}
+
+// Path returns the current path
+func (f *Files) Path() string {
+	return f.paths[f.next-1]
+}
+
+// Paths returns all the paths, regardless of the current one
+func (f *Files) Paths() []string {
+	return f.paths
+}
+
+// Err returns the error that stopped the search for files, if any
+func (f *Files) Err() error {
+	return f.err
+}
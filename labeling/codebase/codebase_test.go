@@ -0,0 +1,115 @@
+package codebase
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/google/go-cmp/cmp"
+)
+
+// allCodebases returns a codebase of each type with files in it
+func allCodebases(t *testing.T, files map[string]string) map[string]Codebase {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, files)
+	gitCodebase, err := NewGitCodebase(repo, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var archiveFiles []archiveFile
+	for name, contents := range files {
+		archiveFiles = append(archiveFiles, archiveFile{name, contents})
+	}
+	zipData := zipArchive(t, archiveFiles)
+	archiveCodebase, err := NewZipCodebase(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inSubDir := make(MapCodebase)
+	for name, contents := range files {
+		inSubDir[filepath.Join("repo", name)] = contents
+	}
+	inSubDir["other/package.json"] = "{}"
+
+	return map[string]Codebase{
+		"LocalCodebase":   NewLocalCodebase(dir),
+		"FSCodebase":      NewFSCodebase(os.DirFS(dir)),
+		"MapCodebase":     MapCodebase(files),
+		"GitCodebase":     gitCodebase,
+		"ArchiveCodebase": archiveCodebase,
+		"SubCodebase":     SubCodebase{Parent: inSubDir, Dir: "repo"},
+	}
+}
+
+func TestCodebase_FindAll_Walk_Stat(t *testing.T) {
+	files := map[string]string{
+		"package.json":     "{}",
+		"web/package.json": `{"name": "web"}`,
+		"api/package.json": "{}",
+		"web/src/index.ts": "",
+		"README.md":        "",
+	}
+
+	for name, c := range allCodebases(t, files) {
+		t.Run(name, func(t *testing.T) {
+			found := c.FindAll("package.json")
+			var got []string
+			for found.Next() {
+				got = append(got, found.Path())
+			}
+			if found.Err() != nil {
+				t.Errorf("got error %v", found.Err())
+			}
+			expected := []string{"package.json", "api/package.json", "web/package.json"}
+			if d := cmp.Diff(expected, got); d != "" {
+				t.Errorf("FindAll() mismatch (-want +got):\n%s", d)
+			}
+
+			if got := c.FindAll("**/*.ts", "!web/**").Paths(); len(got) != 0 {
+				t.Errorf("got %v, expected no files", got)
+			}
+
+			var walked []string
+			err := c.Walk(func(p string) error {
+				walked = append(walked, p)
+				if p == "web/package.json" {
+					return fs.SkipAll
+				}
+				return nil
+			})
+			if err != nil || walked[len(walked)-1] != "web/package.json" {
+				t.Errorf("got %v (error %v), expected the walk to stop at web/package.json", walked, err)
+			}
+			errStop := errors.New("stop")
+			if err = c.Walk(func(string) error { return errStop }); err != errStop {
+				t.Errorf("got error %v, expected the error returned by fn", err)
+			}
+
+			info, err := c.Stat("web/package.json")
+			if d := cmp.Diff(FileInfo{Path: "web/package.json", Size: 15}, info); err != nil || d != "" {
+				t.Errorf("Stat() error %v, mismatch (-want +got):\n%s", err, d)
+			}
+			info, err = c.Stat("web/src")
+			if d := cmp.Diff(FileInfo{Path: "web/src", IsDir: true}, info); err != nil || d != "" {
+				t.Errorf("Stat() error %v, mismatch (-want +got):\n%s", err, d)
+			}
+			if _, err = c.Stat("missing"); !errors.Is(err, NotFoundError) {
+				t.Errorf("got error %v, expected NotFoundError", err)
+			}
+		})
+	}
+}
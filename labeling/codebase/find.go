@@ -0,0 +1,65 @@
+package codebase
+
+import (
+	"io/fs"
+	"sort"
+)
+
+// walkFunc is the Walk method of a Codebase, which FindFileMatching and FindAll are
+// implemented with
+type walkFunc func(fn func(path string) error) error
+
+// findFileMatching returns the first path walk visits that matches a glob and predicate
+func findFileMatching(walk walkFunc, predicate func(string) bool, glob ...string) (string, error) {
+	globs := newGlobSet(glob...)
+	var found string
+	err := walk(func(p string) error {
+		if globs.matches(p) && predicate(p) {
+			found = p
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", NotFoundError
+	}
+	return found, nil
+}
+
+// findAll returns all the paths walk visits that match a glob
+func findAll(walk walkFunc, glob ...string) *Files {
+	globs := newGlobSet(glob...)
+	files := &Files{}
+	files.err = walk(func(p string) error {
+		if globs.matches(p) {
+			files.paths = append(files.paths, p)
+		}
+		return nil
+	})
+	return files
+}
+
+// walkPaths calls fn for each of paths, see Codebase.Walk
+func walkPaths(paths []string, fn func(path string) error) error {
+	for _, p := range paths {
+		err := fn(p)
+		if err == fs.SkipAll {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortByDepth sorts paths so that shallower paths come first, keeping the order of paths
+// with the same depth
+func sortByDepth(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return pathDepth(paths[i]) < pathDepth(paths[j])
+	})
+}
@@ -0,0 +1,165 @@
+package codebase
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FSCodebase is a Codebase for the files in an fs.FS, like an embed.FS or an os.DirFS.
+// Like LocalCodebase, it lists up to maxFiles files, skipping dirs deeper than
+// defaultMaxDepth, and finds the shallowest matching file first.
+type FSCodebase struct {
+	FS fs.FS
+
+	listOnce sync.Once
+	fileSet  []string
+	listErr  error
+}
+
+// NewFSCodebase returns a FSCodebase for fsys
+func NewFSCodebase(fsys fs.FS) *FSCodebase {
+	return &FSCodebase{FS: fsys}
+}
+
+func (c *FSCodebase) FindFileMatching(
+	predicate func(string) bool,
+	glob ...string,
+) (string, error) {
+	return findFileMatching(c.Walk, predicate, glob...)
+}
+
+// files lists the files in FS the first time it's called
+func (c *FSCodebase) files() ([]string, error) {
+	c.listOnce.Do(func() {
+		err := fs.WalkDir(c.FS, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == "." {
+				return nil
+			}
+			if d.IsDir() && pathDepth(p) > defaultMaxDepth {
+				return fs.SkipDir
+			}
+			c.fileSet = append(c.fileSet, p)
+			if len(c.fileSet) >= maxFiles {
+				return fs.SkipAll
+			}
+			return nil
+		})
+		sortByDepth(c.fileSet)
+		c.listErr = err
+	})
+	return c.fileSet, c.listErr
+}
+
+func (c *FSCodebase) FindFile(glob ...string) (path string, err error) {
+	return c.FindFileMatching(func(string) bool { return true }, glob...)
+}
+
+func (c *FSCodebase) FindAll(glob ...string) *Files {
+	return findAll(c.Walk, glob...)
+}
+
+func (c *FSCodebase) Walk(fn func(path string) error) error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	return walkPaths(files, fn)
+}
+
+func (c *FSCodebase) Stat(filePath string) (FileInfo, error) {
+	filePath = path.Clean(filePath)
+	info, err := fs.Stat(c.FS, filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return FileInfo{}, NotFoundError
+	}
+	if err != nil {
+		return FileInfo{}, err
+	}
+	fileInfo := FileInfo{Path: filePath, IsDir: info.IsDir()}
+	if !info.IsDir() {
+		fileInfo.Size = info.Size()
+	}
+	return fileInfo, nil
+}
+
+func (c *FSCodebase) ReadFile(filePath string) (contents []byte, err error) {
+	contents, err = fs.ReadFile(c.FS, path.Clean(filePath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, NotFoundError
+	}
+	return contents, err
+}
+
+// MapCodebase is a Codebase for files held in memory, as a map of path to contents.
+// Paths use forward slashes and are relative to the root of the codebase, like "go.mod"
+// or "cmd/main.go". Dirs are not listed, only files.
+type MapCodebase map[string]string
+
+func (c MapCodebase) FindFileMatching(
+	predicate func(string) bool,
+	glob ...string,
+) (string, error) {
+	return findFileMatching(c.Walk, predicate, glob...)
+}
+
+func (c MapCodebase) FindFile(glob ...string) (path string, err error) {
+	return c.FindFileMatching(func(string) bool { return true }, glob...)
+}
+
+func (c MapCodebase) FindAll(glob ...string) *Files {
+	return findAll(c.Walk, glob...)
+}
+
+func (c MapCodebase) Walk(fn func(path string) error) error {
+	files := make([]string, 0, len(c))
+	for p := range c {
+		files = append(files, p)
+	}
+	sort.Strings(files)
+	sortByDepth(files)
+	return walkPaths(files, fn)
+}
+
+func (c MapCodebase) Stat(filePath string) (FileInfo, error) {
+	return statInFiles(filePath, func(p string) (int64, bool) {
+		contents, ok := c[p]
+		return int64(len(contents)), ok
+	}, c.Walk)
+}
+
+func (c MapCodebase) ReadFile(filePath string) (contents []byte, err error) {
+	s, ok := c[path.Clean(filePath)]
+	if !ok {
+		return nil, NotFoundError
+	}
+	return []byte(s), nil
+}
+
+// statInFiles returns the FileInfo of filePath, for codebases that only list files: a file
+// if size finds it, a dir if walk visits files under it, or NotFoundError otherwise
+func statInFiles(filePath string, size func(string) (int64, bool), walk walkFunc) (FileInfo, error) {
+	filePath = path.Clean(filePath)
+	if s, ok := size(filePath); ok {
+		return FileInfo{Path: filePath, Size: s}, nil
+	}
+
+	isDir := filePath == "."
+	_ = walk(func(p string) error {
+		if strings.HasPrefix(p, filePath+"/") {
+			isDir = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if !isDir {
+		return FileInfo{}, NotFoundError
+	}
+	return FileInfo{Path: filePath, IsDir: true}, nil
+}
@@ -0,0 +1,61 @@
+package codebase
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSCodebase_and_MapCodebase(t *testing.T) {
+	files := map[string]string{
+		"b/c/go.mod":             "module deeper",
+		"a/go.mod":               "module shallower",
+		"a/b/c/d/e/package.json": "{}",
+		"empty.txt":              "",
+	}
+	mapFS := fstest.MapFS{}
+	for p, contents := range files {
+		mapFS[p] = &fstest.MapFile{Data: []byte(contents)}
+	}
+	codebases := map[string]Codebase{
+		"FSCodebase":  NewFSCodebase(mapFS),
+		"MapCodebase": MapCodebase(files),
+	}
+
+	for name, c := range codebases {
+		t.Run(name, func(t *testing.T) {
+			found, err := c.FindFile("go.mod")
+			if err != nil || found != "a/go.mod" {
+				t.Errorf("got %q (error %v), expected the shallowest go.mod, a/go.mod", found, err)
+			}
+
+			found, err = c.FindFileMatching(func(p string) bool { return p != "a/go.mod" }, "go.mod")
+			if err != nil || found != "b/c/go.mod" {
+				t.Errorf("got %q (error %v), expected b/c/go.mod", found, err)
+			}
+
+			if found, err = c.FindFile("Cargo.toml"); !errors.Is(err, NotFoundError) {
+				t.Errorf("got %q (error %v), expected NotFoundError", found, err)
+			}
+
+			contents, err := c.ReadFile("b/c/go.mod")
+			if err != nil || string(contents) != "module deeper" {
+				t.Errorf("got %q (error %v), expected the contents of b/c/go.mod", contents, err)
+			}
+
+			contents, err = c.ReadFile("empty.txt")
+			if err != nil || len(contents) != 0 {
+				t.Errorf("got %q (error %v), expected an empty file", contents, err)
+			}
+
+			if _, err = c.ReadFile("missing"); !errors.Is(err, NotFoundError) {
+				t.Errorf("got error %v reading a missing file, expected NotFoundError", err)
+			}
+		})
+	}
+
+	// like LocalCodebase, dirs deeper than defaultMaxDepth are skipped
+	if found, err := codebases["FSCodebase"].FindFile("package.json"); !errors.Is(err, NotFoundError) {
+		t.Errorf("got %q (error %v), expected files deeper than the max depth to be skipped", found, err)
+	}
+}
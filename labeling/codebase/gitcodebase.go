@@ -0,0 +1,167 @@
+package codebase
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GitCodebase is a Codebase for the files of a git repository at a given revision, read
+// from the repository objects instead of a working tree. It works with bare repositories
+// and mirrors too. Use NewGitCodebase or OpenGitCodebase to create one.
+type GitCodebase struct {
+	tree      *object.Tree
+	fileSet   []string
+	maxFiles  int
+	truncated bool
+
+	// go-git object storage is not safe for concurrent use
+	mu sync.Mutex
+}
+
+// OpenGitCodebase opens the git repository at repoPath, which can be a bare repository,
+// and returns a GitCodebase for it at revision. See NewGitCodebase for the revisions
+// it accepts.
+func OpenGitCodebase(repoPath string, revision string) (*GitCodebase, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository %s: %w", repoPath, err)
+	}
+	return NewGitCodebase(repo, revision)
+}
+
+// NewGitCodebase returns a GitCodebase for repo at revision, which can be anything git
+// rev-parse understands and resolves to a commit, like a commit hash, a branch or tag name,
+// or "HEAD~2". An empty revision means HEAD.
+func NewGitCodebase(repo *git.Repository, revision string) (*GitCodebase, error) {
+	if revision == "" {
+		revision = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("resolving revision %s: %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading tree of commit %s: %w", hash, err)
+	}
+
+	return newGitCodebase(tree, maxFiles)
+}
+
+// newGitCodebase returns a GitCodebase listing up to maxFileCount of the files in tree
+func newGitCodebase(tree *object.Tree, maxFileCount int) (*GitCodebase, error) {
+	c := &GitCodebase{tree: tree, maxFiles: maxFileCount}
+	err := c.listFiles(tree, ".", 0)
+	if errors.Is(err, errTooManyFiles) {
+		c.truncated = true
+	} else if err != nil {
+		return nil, err
+	}
+	sortByDepth(c.fileSet)
+	return c, nil
+}
+
+// Truncated returns true if there were more files than the max files, so some were left
+// out, like LocalCodebase.Truncated
+func (c *GitCodebase) Truncated() bool {
+	return c.truncated
+}
+
+var errTooManyFiles = errors.New("too many files")
+
+// listFiles adds the paths in tree to fileSet, like LocalCodebase does for a dir on disk:
+// dirs deeper than defaultMaxDepth are skipped, and at most c.maxFiles paths are listed
+func (c *GitCodebase) listFiles(tree *object.Tree, dir string, depth int) error {
+	for _, entry := range tree.Entries {
+		p := path.Join(dir, entry.Name)
+		if entry.Mode == filemode.Dir && depth+1 > defaultMaxDepth {
+			continue
+		}
+		if len(c.fileSet) >= c.maxFiles {
+			return errTooManyFiles
+		}
+		c.fileSet = append(c.fileSet, p)
+		if entry.Mode != filemode.Dir {
+			// files, symlinks and submodules
+			continue
+		}
+
+		subtree, err := tree.Tree(entry.Name)
+		if err != nil {
+			return fmt.Errorf("reading tree %s: %w", p, err)
+		}
+		err = c.listFiles(subtree, p, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *GitCodebase) FindFileMatching(
+	predicate func(string) bool,
+	glob ...string,
+) (string, error) {
+	return findFileMatching(c.Walk, predicate, glob...)
+}
+
+func (c *GitCodebase) FindAll(glob ...string) *Files {
+	return findAll(c.Walk, glob...)
+}
+
+func (c *GitCodebase) Walk(fn func(path string) error) error {
+	return walkPaths(c.fileSet, fn)
+}
+
+func (c *GitCodebase) Stat(filePath string) (FileInfo, error) {
+	filePath = path.Clean(filePath)
+	if filePath == "." {
+		return FileInfo{Path: filePath, IsDir: true}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, err := c.tree.FindEntry(filePath)
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return FileInfo{}, NotFoundError
+	}
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if entry.Mode == filemode.Dir {
+		return FileInfo{Path: filePath, IsDir: true}, nil
+	}
+	size, err := c.tree.Size(filePath)
+	return FileInfo{Path: filePath, Size: size}, err
+}
+
+func (c *GitCodebase) FindFile(glob ...string) (path string, err error) {
+	return c.FindFileMatching(func(string) bool { return true }, glob...)
+}
+
+func (c *GitCodebase) ReadFile(filePath string) (contents []byte, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := c.tree.File(path.Clean(filePath))
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, NotFoundError
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := f.Contents()
+	return []byte(s), err
+}
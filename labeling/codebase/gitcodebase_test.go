@@ -0,0 +1,164 @@
+package codebase
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFiles writes files to the worktree of repo and commits them
+func commitFiles(t *testing.T, repo *git.Repository, files map[string]string) {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		p := filepath.Join(wt.Filesystem.Root(), name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = wt.Commit("commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitCodebase(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, map[string]string{
+		"go.mod":                   "module v1",
+		"cmd/app/main.go":          "package main",
+		"a/b/c/d/too-deep/file.go": "package deep",
+	})
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.CreateTag("v1", head.Hash(), nil); err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, map[string]string{"go.mod": "module v2", "package.json": "{}"})
+
+	bareDir := t.TempDir()
+	_, err = git.PlainClone(bareDir, true, &git.CloneOptions{URL: dir, Tags: git.AllTags})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		repoPath         string
+		revision         string
+		expectedGoMod    string
+		expectedFindNode string
+	}{
+		{
+			name:             "HEAD",
+			repoPath:         dir,
+			expectedGoMod:    "module v2",
+			expectedFindNode: "package.json",
+		}, {
+			name:          "tag",
+			repoPath:      dir,
+			revision:      "v1",
+			expectedGoMod: "module v1",
+		}, {
+			name:          "parent commit",
+			repoPath:      dir,
+			revision:      "HEAD~1",
+			expectedGoMod: "module v1",
+		}, {
+			name:             "bare repository",
+			repoPath:         bareDir,
+			revision:         "master",
+			expectedGoMod:    "module v2",
+			expectedFindNode: "package.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := OpenGitCodebase(tt.repoPath, tt.revision)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			contents, err := c.ReadFile("go.mod")
+			if err != nil || string(contents) != tt.expectedGoMod {
+				t.Errorf("got go.mod %q (error %v), expected %q", contents, err, tt.expectedGoMod)
+			}
+
+			found, _ := c.FindFile("package.json")
+			if found != tt.expectedFindNode {
+				t.Errorf("got package.json at %q, expected %q", found, tt.expectedFindNode)
+			}
+
+			found, err = c.FindFile("*.go")
+			if err != nil || found != "cmd/app/main.go" {
+				t.Errorf("got *.go at %q (error %v), expected cmd/app/main.go", found, err)
+			}
+
+			if found, err = c.FindFile("file.go"); !errors.Is(err, NotFoundError) {
+				t.Errorf("got %q (error %v), expected files deeper than the max depth to be skipped", found, err)
+			}
+
+			if _, err = c.ReadFile("missing"); !errors.Is(err, NotFoundError) {
+				t.Errorf("got error %v reading a missing file, expected NotFoundError", err)
+			}
+		})
+	}
+
+	if _, err := OpenGitCodebase(dir, "no-such-branch"); err == nil {
+		t.Errorf("got no error for an unknown revision")
+	}
+}
+
+func TestGitCodebase_Truncated(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, map[string]string{"a.txt": "", "b.txt": "", "c.txt": ""})
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for maxFileCount, expected := range map[int]bool{2: true, 3: false} {
+		c, err := newGitCodebase(tree, maxFileCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Truncated() != expected || len(c.fileSet) > maxFileCount {
+			t.Errorf("got %v (truncated %v) for %d max files, expected truncated %v",
+				c.fileSet, c.Truncated(), maxFileCount, expected)
+		}
+	}
+}
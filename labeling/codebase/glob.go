@@ -0,0 +1,127 @@
+package codebase
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// globSet matches paths against a list of globs, as taken by Codebase.FindFileMatching.
+// On top of the syntax of path.Match, globs can have:
+//   - "**" segments, matching any number of dirs, like "**/src/test/**/*.java"
+//   - brace alternatives, like "*.{yml,yaml}"
+//   - a leading "!" to exclude the paths matching the rest of the glob
+//
+// A path matches if it matches any of the globs that are not negated, or if they all
+// are negated, and it matches none of the negated ones. Globs without a slash match the
+// base name of the path, others match the whole path, from the root of the codebase.
+type globSet struct {
+	include []glob
+	exclude []glob
+}
+
+// glob is a single glob, without braces, split into "/"-separated segments
+type glob []string
+
+func newGlobSet(globs ...string) globSet {
+	var s globSet
+	for _, g := range globs {
+		negated := strings.HasPrefix(g, "!")
+		g = strings.TrimPrefix(g, "!")
+		for _, expanded := range expandBraces(g) {
+			expanded = strings.TrimPrefix(expanded, "/")
+			if negated {
+				s.exclude = append(s.exclude, strings.Split(expanded, "/"))
+			} else {
+				s.include = append(s.include, strings.Split(expanded, "/"))
+			}
+		}
+	}
+	return s
+}
+
+func (s globSet) matches(p string) bool {
+	parts := strings.Split(filepath.ToSlash(p), "/")
+	for _, g := range s.exclude {
+		if g.matches(parts) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return len(s.exclude) > 0
+	}
+	for _, g := range s.include {
+		if g.matches(parts) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g glob) matches(parts []string) bool {
+	if len(g) == 1 && g[0] != "**" {
+		return matchSegment(g[0], parts[len(parts)-1])
+	}
+	return matchSegments(g, parts)
+}
+
+// matchSegments returns true if the glob segments match all the path parts
+func matchSegments(g []string, parts []string) bool {
+	for len(g) > 0 {
+		if g[0] == "**" {
+			// try "**" matching no parts, then one more part at a time
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(g[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 || !matchSegment(g[0], parts[0]) {
+			return false
+		}
+		g, parts = g[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+func matchSegment(pattern string, name string) bool {
+	matches, _ := path.Match(pattern, name)
+	return matches
+}
+
+// expandBraces returns the globs a glob with brace alternatives stands for, e.g.
+// "*.{yml,yaml}" stands for "*.yml" and "*.yaml". Braces can be nested.
+func expandBraces(g string) []string {
+	open := strings.Index(g, "{")
+	if open < 0 {
+		return []string{g}
+	}
+
+	depth := 0
+	alternatives := []string{}
+	start := open + 1
+	for i := open; i < len(g); i++ {
+		switch g[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				alternatives = append(alternatives, g[start:i])
+				var expanded []string
+				for _, alt := range alternatives {
+					expanded = append(expanded, expandBraces(g[:open]+alt+g[i+1:])...)
+				}
+				return expanded
+			}
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, g[start:i])
+				start = i + 1
+			}
+		}
+	}
+	// unbalanced braces are taken literally
+	return []string{g}
+}
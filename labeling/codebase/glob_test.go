@@ -0,0 +1,55 @@
+package codebase
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGlobSet_matches(t *testing.T) {
+	tests := []struct {
+		globs    []string
+		path     string
+		expected bool
+	}{
+		{globs: []string{"go.mod"}, path: "go.mod", expected: true},
+		{globs: []string{"go.mod"}, path: "cmd/go.mod", expected: true},
+		{globs: []string{"*.go"}, path: "cmd/app/main.go", expected: true},
+		{globs: []string{"cmd/*.go"}, path: "cmd/main.go", expected: true},
+		{globs: []string{"cmd/*.go"}, path: "app/cmd/main.go", expected: false},
+		{globs: []string{"apps/*/package.json"}, path: "apps/web/package.json", expected: true},
+		{globs: []string{"apps/*/package.json"}, path: "apps/web/x/package.json", expected: false},
+		{globs: []string{"**/project.clj"}, path: "project.clj", expected: true},
+		{globs: []string{"/**/project.clj"}, path: "a/b/project.clj", expected: true},
+		{globs: []string{"**/src/test/**/*.java"}, path: "src/test/FooTest.java", expected: true},
+		{globs: []string{"**/src/test/**/*.java"}, path: "mod/src/test/a/b/FooTest.java", expected: true},
+		{globs: []string{"**/src/test/**/*.java"}, path: "mod/src/main/Foo.java", expected: false},
+		{globs: []string{"docs/**"}, path: "docs/a/b.md", expected: true},
+		{globs: []string{"*.{yml,yaml}"}, path: ".github/workflows/ci.yaml", expected: true},
+		{globs: []string{"*.{yml,yaml}"}, path: "ci.json", expected: false},
+		{globs: []string{"{a,b/{c,d}}/x"}, path: "b/d/x", expected: true},
+		{globs: []string{"{a,b"}, path: "{a,b", expected: true},
+		{globs: []string{"package.json", "!node_modules/**"}, path: "web/package.json", expected: true},
+		{globs: []string{"package.json", "!node_modules/**"}, path: "node_modules/x/package.json", expected: false},
+		{globs: []string{"!*.md"}, path: "main.go", expected: true},
+		{globs: []string{"!*.md"}, path: "README.md", expected: false},
+		{globs: nil, path: "main.go", expected: false},
+	}
+	for _, tt := range tests {
+		if got := newGlobSet(tt.globs...).matches(tt.path); got != tt.expected {
+			t.Errorf("globs %q matching %q: got %v, expected %v", tt.globs, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	got := expandBraces("src/{main,test/{unit,it}}/*.{kt,java}")
+	expected := []string{
+		"src/main/*.kt", "src/main/*.java",
+		"src/test/unit/*.kt", "src/test/unit/*.java",
+		"src/test/it/*.kt", "src/test/it/*.java",
+	}
+	if d := cmp.Diff(expected, got); d != "" {
+		t.Errorf("expandBraces() mismatch (-want +got):\n%s", d)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 )
 
 const maxFiles = 250000
@@ -14,92 +16,304 @@ const defaultMaxDepth = 3
 
 var NotFoundError = errors.New("not found")
 
-// LocalCodebase is a Codebase for files available on local disk
+// DefaultIgnoredDirs are the dirs LocalCodebase skips unless told otherwise: git metadata,
+// and the dependencies and build outputs of well-known tools, which hold files like the
+// package.json of dependencies that would be mistaken for the codebase's own
+var DefaultIgnoredDirs = []string{".git", "node_modules", "vendor", ".venv", "target", "dist"}
+
+// LocalCodebase is a Codebase for files available on local disk.
+// Files ignored by .gitignore files or .git/info/exclude, and dirs in DefaultIgnoredDirs,
+// are skipped. Files are listed the first time they're needed, and then cached.
+// Use NewLocalCodebase to create one. As it caches the files it lists, only a
+// *LocalCodebase implements Codebase.
 type LocalCodebase struct {
-	BasePath string
-	maxDepth int // including root dir
-	fileSet  []string
+	// Deprecated: use NewLocalCodebase rather than setting BasePath, as a LocalCodebase
+	// value, like LocalCodebase{BasePath: dir}, doesn't implement Codebase.
+	BasePath    string
+	ignoredDirs []string // nil means DefaultIgnoredDirs
+	noGitignore bool
+	maxDepth    *int // nil means defaultMaxDepth
+	maxFiles    int
+	symlinks    SymlinkPolicy
+
+	listOnce  sync.Once
+	fileSet   []string
+	truncated bool
+	listErr   error
+}
+
+// SymlinkPolicy is what LocalCodebase does with symlinks
+type SymlinkPolicy int
+
+const (
+	// ListSymlinks lists symlinks like files, without following symlinks to dirs
+	ListSymlinks SymlinkPolicy = iota
+	// SkipSymlinks leaves symlinks out
+	SkipSymlinks
+	// FollowSymlinks lists the files in symlinked dirs as if they were in the dir of the
+	// symlink, e.g. a package.json in a dir shared by several repos. To avoid loops,
+	// symlinks to a dir the symlink is in are not followed, nor is a dir followed twice.
+	FollowSymlinks
+)
+
+// LocalCodebaseOption changes how NewLocalCodebase scans the files on disk
+type LocalCodebaseOption func(*LocalCodebase)
+
+// WithMaxDepth scans the files in dirs up to maxDepth levels under the root dir, e.g. with
+// a maxDepth of 1, "src/main.go" is found but "src/pkg/main.go" is not, and with a maxDepth
+// of 0, only the files in the root dir are. The default is 3.
+func WithMaxDepth(maxDepth int) LocalCodebaseOption {
+	return func(c *LocalCodebase) { c.maxDepth = &maxDepth }
+}
+
+// WithMaxFiles stops the scan after maxFiles files and dirs, see LocalCodebase.Truncated.
+// The default is 250000.
+func WithMaxFiles(maxFiles int) LocalCodebaseOption {
+	return func(c *LocalCodebase) { c.maxFiles = maxFiles }
+}
+
+// WithSymlinks sets what to do with symlinks. The default is ListSymlinks.
+func WithSymlinks(policy SymlinkPolicy) LocalCodebaseOption {
+	return func(c *LocalCodebase) { c.symlinks = policy }
+}
+
+// WithIgnoredDirs skips the dirs with any of these names, wherever they are, instead of
+// DefaultIgnoredDirs. Pass no dirs to not skip any.
+func WithIgnoredDirs(dirs ...string) LocalCodebaseOption {
+	return func(c *LocalCodebase) { c.ignoredDirs = append([]string{}, dirs...) }
+}
+
+// WithoutGitignore includes the files that .gitignore and .git/info/exclude ignore
+func WithoutGitignore() LocalCodebaseOption {
+	return func(c *LocalCodebase) { c.noGitignore = true }
 }
 
-func (c LocalCodebase) FindFileMatching(
+// NewLocalCodebase returns a LocalCodebase for the files under basePath
+func NewLocalCodebase(basePath string, opts ...LocalCodebaseOption) *LocalCodebase {
+	c := &LocalCodebase{BasePath: basePath}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *LocalCodebase) FindFileMatching(
 	predicate func(string) bool,
 	glob ...string,
 ) (string, error) {
+	return findFileMatching(c.Walk, predicate, glob...)
+}
+
+func (c *LocalCodebase) FindAll(glob ...string) *Files {
+	return findAll(c.Walk, glob...)
+}
+
+func (c *LocalCodebase) Walk(fn func(path string) error) error {
 	files, err := c.files()
 	if err != nil {
-		return "", err
+		return err
 	}
+	return walkPaths(files, fn)
+}
 
-	for _, path := range files {
-		for _, g := range glob {
-			matchesName, _ := filepath.Match(g, filepath.Base(path))
-			matchesPath, _ := filepath.Match(g, path)
-			if !(matchesName || matchesPath) {
-				continue
-			}
-			if predicate(path) {
-				return path, err
-			}
-		}
+func (c *LocalCodebase) Stat(path string) (FileInfo, error) {
+	info, err := os.Stat(filepath.Join(c.BasePath, path))
+	if os.IsNotExist(err) {
+		return FileInfo{}, NotFoundError
 	}
+	if err != nil {
+		return FileInfo{}, err
+	}
+	fileInfo := FileInfo{Path: filepath.Clean(path), IsDir: info.IsDir()}
+	if !info.IsDir() {
+		fileInfo.Size = info.Size()
+	}
+	return fileInfo, nil
+}
 
-	return "", NotFoundError
-
+// Truncated returns true if there were more files than the max files, so some were left
+// out. It lists the files if they haven't been yet.
+func (c *LocalCodebase) Truncated() bool {
+	_, _ = c.files()
+	return c.truncated
 }
 
-func (c LocalCodebase) files() ([]string, error) {
-	if len(c.fileSet) > 0 {
-		return c.fileSet, nil
-	}
+func (c *LocalCodebase) files() ([]string, error) {
+	c.listOnce.Do(func() {
+		c.fileSet, c.truncated, c.listErr = c.listFiles()
+	})
+	return c.fileSet, c.listErr
+}
 
+func (c *LocalCodebase) listFiles() (fileList []string, truncated bool, err error) {
 	basePath := c.BasePath
 	if basePath == "" {
 		basePath = "."
 	}
 
-	if c.maxDepth == 0 {
-		c.maxDepth = defaultMaxDepth
+	maxDepth := defaultMaxDepth
+	if c.maxDepth != nil {
+		maxDepth = *c.maxDepth
+	}
+	maxFileCount := c.maxFiles
+	if maxFileCount == 0 {
+		maxFileCount = maxFiles
 	}
 
-	filesVisited := 0
-	var fileList []string
-	err := filepath.WalkDir(
-		basePath,
-		func(path string, d fs.DirEntry, fileError error) error {
-			if fileError != nil {
-				return fileError
-			}
-			relPath, innerErr := filepath.Rel(c.BasePath, path)
-			if d.IsDir() && pathDepth(relPath) > c.maxDepth {
-				return filepath.SkipDir
-			}
-			if innerErr != nil {
-				return innerErr
-			}
-			fileList = append(fileList, relPath)
-			filesVisited++
-			if filesVisited >= maxFiles {
-				return filepath.SkipAll
+	ignoredDirs := c.ignoredDirs
+	if ignoredDirs == nil {
+		ignoredDirs = DefaultIgnoredDirs
+	}
+	// patterns and matchers of the gitignore files that apply to each dir, i.e. the ones in
+	// the dir and its parents, built once per dir for the files in it
+	ignorePatterns := make(map[string][]gitignore.Pattern)
+	ignoreMatchers := make(map[string]gitignore.Matcher)
+	if !c.noGitignore {
+		ignorePatterns["."] = readIgnoreFile(basePath, filepath.Join(".git", "info", "exclude"))
+		ignoreMatchers["."] = gitignore.NewMatcher(ignorePatterns["."])
+	}
+
+	// real paths of the root and the symlinked dirs followed so far
+	followed := make(map[string]bool)
+	if realBase, realErr := filepath.EvalSymlinks(basePath); realErr == nil {
+		followed[realBase] = true
+	}
+
+	// walk lists the files under root, which is at relRoot in the codebase
+	var walk func(root string, relRoot string) error
+	walk = func(root string, relRoot string) error {
+		return filepath.WalkDir(
+			root,
+			func(path string, d fs.DirEntry, fileError error) error {
+				if fileError != nil {
+					return fileError
+				}
+				relPath, innerErr := filepath.Rel(root, path)
+				if innerErr != nil {
+					return innerErr
+				}
+				relPath = filepath.Join(relRoot, relPath)
+				if d.IsDir() && relPath != "." && pathDepth(relPath) > maxDepth {
+					return filepath.SkipDir
+				}
+				parent := filepath.Dir(relPath)
+				if path == root && relRoot != "." {
+					// the symlink, listed already, whose files the patterns of its dir apply to
+					ignorePatterns[relPath] = ignorePatterns[parent]
+					ignoreMatchers[relPath] = ignoreMatchers[parent]
+					return nil
+				}
+				isSymlink := d.Type()&fs.ModeSymlink != 0
+				if isSymlink && c.symlinks == SkipSymlinks {
+					return nil
+				}
+				if relPath != "." && isIgnored(relPath, d, ignoredDirs, ignoreMatchers[parent]) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() && !c.noGitignore {
+					ignorePatterns[relPath] = ignorePatterns[parent]
+					ignoreMatchers[relPath] = ignoreMatchers[parent]
+					if patterns := readIgnoreFile(basePath, filepath.Join(relPath, ".gitignore")); len(patterns) > 0 {
+						// a copy, not to append to the patterns of the parent
+						patterns = append(append([]gitignore.Pattern{}, ignorePatterns[parent]...), patterns...)
+						ignorePatterns[relPath] = patterns
+						ignoreMatchers[relPath] = gitignore.NewMatcher(patterns)
+					}
+				}
+				if len(fileList) >= maxFileCount {
+					truncated = true
+					return filepath.SkipAll
+				}
+				fileList = append(fileList, relPath)
+
+				if isSymlink && c.symlinks == FollowSymlinks && pathDepth(relPath) <= maxDepth {
+					if target, ok := symlinkedDir(path, followed); ok {
+						followed[target] = true
+						return walk(target, relPath)
+					}
+				}
+				return nil
+			})
+	}
+	err = walk(basePath, ".")
+
+	sortByDepth(fileList)
+	return fileList, truncated, err
+}
+
+// symlinkedDir returns the real path of the dir the symlink at path points to, if it
+// should be followed: it's not a dir in followed, nor a dir the symlink is in
+func symlinkedDir(path string, followed map[string]bool) (string, bool) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	info, err := os.Stat(target)
+	if err != nil || !info.IsDir() || followed[target] {
+		return "", false
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", false
+	}
+	if parent == target || strings.HasPrefix(parent, target+string(os.PathSeparator)) {
+		return "", false
+	}
+	return target, true
+}
+
+// isIgnored returns true if relPath is one of ignoredDirs, or matches the patterns of
+// ignoreMatcher, if any
+func isIgnored(relPath string, d fs.DirEntry, ignoredDirs []string, ignoreMatcher gitignore.Matcher) bool {
+	if d.IsDir() {
+		for _, dir := range ignoredDirs {
+			if d.Name() == dir {
+				return true
 			}
-			return nil
-		})
+		}
+	}
+	if ignoreMatcher == nil {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	return ignoreMatcher.Match(parts, d.IsDir())
+}
 
-	sort.Slice(fileList, func(i, j int) bool {
-		return pathDepth(fileList[i]) < pathDepth(fileList[j])
-	})
-	c.fileSet = fileList
+// readIgnoreFile returns the patterns in a file with the syntax of .gitignore, at relPath
+// from basePath. The patterns only apply to the dir the file is in, except for
+// .git/info/exclude, which applies to the whole codebase.
+func readIgnoreFile(basePath string, relPath string) []gitignore.Pattern {
+	contents, err := os.ReadFile(filepath.Join(basePath, relPath))
+	if err != nil {
+		return nil
+	}
 
-	return c.fileSet, err
+	var domain []string
+	if dir := filepath.ToSlash(filepath.Dir(relPath)); dir != "." && dir != ".git/info" {
+		domain = strings.Split(dir, "/")
+	}
+	var patterns []gitignore.Pattern
+	for _, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+	return patterns
 }
 
 func pathDepth(path string) int {
 	return strings.Count(path, string(os.PathSeparator)) + 1
 }
 
-func (c LocalCodebase) FindFile(glob ...string) (path string, err error) {
+func (c *LocalCodebase) FindFile(glob ...string) (path string, err error) {
 	return c.FindFileMatching(func(string) bool { return true }, glob...)
 }
 
-func (c LocalCodebase) ReadFile(path string) (contents []byte, err error) {
+func (c *LocalCodebase) ReadFile(path string) (contents []byte, err error) {
 	return os.ReadFile(filepath.Join(c.BasePath, path))
 }
@@ -1,8 +1,12 @@
 package codebase
 
 import (
+	"os"
 	"path/filepath"
+	"sort"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestLocalCodebase_FindFileMatching(t *testing.T) {
@@ -36,7 +40,7 @@ func TestLocalCodebase_FindFileMatching(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := LocalCodebase{BasePath: tt.BasePath}
+			c := NewLocalCodebase(tt.BasePath)
 			gotPath, err := c.FindFileMatching(tt.predicate, tt.globs...)
 			if (err != nil) != tt.expectErr {
 				t.Errorf("FindFile() error %v, expectErr %v", err, tt.expectErr)
@@ -100,7 +104,7 @@ func TestLocalCodebase_FindFile(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := LocalCodebase{BasePath: tt.BasePath}
+			c := NewLocalCodebase(tt.BasePath)
 			gotPath, err := c.FindFile(tt.globs...)
 			if (err != nil) != tt.expectErr {
 				t.Errorf("FindFile() error %v, expectErr %v", err, tt.expectErr)
@@ -141,7 +145,7 @@ func TestLocalCodebase_ReadFile(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := LocalCodebase{BasePath: tt.BasePath}
+			c := NewLocalCodebase(tt.BasePath)
 			gotContents, err := c.ReadFile(tt.path)
 			if (err != nil) != tt.expectErr {
 				t.Errorf("ReadFile() error %v, expectErr %v", err, tt.expectErr)
@@ -157,7 +161,7 @@ func TestLocalCodebase_ReadFile(t *testing.T) {
 
 func TestLocalCodebase_files(t *testing.T) {
 	t.Run("doesn't error", func(t *testing.T) {
-		_, err := LocalCodebase{BasePath: "../.."}.files()
+		_, err := NewLocalCodebase("../..").files()
 		if err != nil {
 			t.Errorf("got %v, expected no error", err)
 			return
@@ -165,7 +169,7 @@ func TestLocalCodebase_files(t *testing.T) {
 	})
 
 	t.Run("README.md (at the root) comes before this file (localcodebase_test.go)", func(t *testing.T) {
-		gotFiles, _ := LocalCodebase{BasePath: "../.."}.files()
+		gotFiles, _ := NewLocalCodebase("../..").files()
 		thisFilePos := -1
 		readmePos := -1
 		for i, f := range gotFiles {
@@ -189,7 +193,7 @@ func TestLocalCodebase_files(t *testing.T) {
 	})
 
 	t.Run("finds testdata contents", func(t *testing.T) {
-		gotFiles, _ := LocalCodebase{BasePath: "../..", maxDepth: 3}.files()
+		gotFiles, _ := NewLocalCodebase("../..", WithMaxDepth(3)).files()
 
 		for _, f := range gotFiles {
 			if filepath.Base(f) == "find.me" {
@@ -202,7 +206,7 @@ func TestLocalCodebase_files(t *testing.T) {
 	})
 
 	t.Run("does not find testdata contents (too deep)", func(t *testing.T) {
-		gotFiles, _ := LocalCodebase{BasePath: "../..", maxDepth: 2}.files()
+		gotFiles, _ := NewLocalCodebase("../..", WithMaxDepth(2)).files()
 
 		for _, f := range gotFiles {
 			if filepath.Base(f) == "find.me" {
@@ -214,3 +218,173 @@ func TestLocalCodebase_files(t *testing.T) {
 	})
 
 }
+
+// writeFiles writes files, a map of path to contents, under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLocalCodebase_ignored(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".gitignore":                     "/build/\n# comment\n*.log\n",
+		".git/info/exclude":              "local.json\n",
+		"node_modules/jest/package.json": "{}",
+		"vendor/github.com/x/main.go":    "package main",
+		"build/package.json":             "{}",
+		"web/.gitignore":                 "generated/\n!keep.log\n",
+		"web/generated/package.json":     "{}",
+		"web/keep.log":                   "",
+		"web/other.log":                  "",
+		"web/local.json":                 "",
+		"web/package.json":               "{}",
+		"docs/build/package.json":        "{}",
+	})
+
+	tests := []struct {
+		name     string
+		codebase *LocalCodebase
+		expected []string
+	}{
+		{
+			name:     "default",
+			codebase: NewLocalCodebase(dir),
+			expected: []string{"web/keep.log", "web/package.json", "docs/build/package.json"},
+		}, {
+			name:     "no ignored dirs",
+			codebase: NewLocalCodebase(dir, WithIgnoredDirs()),
+			expected: []string{
+				"web/keep.log", "web/package.json", "docs/build/package.json",
+				"node_modules/jest/package.json", "vendor/github.com/x/main.go",
+			},
+		}, {
+			name:     "no gitignore",
+			codebase: NewLocalCodebase(dir, WithoutGitignore()),
+			expected: []string{
+				"build/package.json", "web/keep.log", "web/local.json", "web/other.log", "web/package.json",
+				"docs/build/package.json", "web/generated/package.json",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			_, _ = tt.codebase.FindFileMatching(func(p string) bool {
+				got = append(got, p)
+				return false
+			}, "*.json", "*.log", "*.go")
+
+			sort.Strings(got)
+			sort.Strings(tt.expected)
+			if d := cmp.Diff(tt.expected, got); d != "" {
+				t.Errorf("files mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestNewLocalCodebase(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.txt":         "",
+		"b.txt":         "",
+		"sub/c.txt":     "",
+		"sub/sub/d.txt": "",
+	})
+	if err := os.Symlink(filepath.Join(dir, "a.txt"), filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("files are cached", func(t *testing.T) {
+		c := NewLocalCodebase(dir)
+		if _, err := c.FindFile("a.txt"); err != nil {
+			t.Fatal(err)
+		}
+		writeFiles(t, dir, map[string]string{"new.txt": ""})
+		defer os.Remove(filepath.Join(dir, "new.txt"))
+		if found, err := c.FindFile("new.txt"); err == nil {
+			t.Errorf("got %q, expected files added after the first scan not to be found", found)
+		}
+	})
+
+	t.Run("max files", func(t *testing.T) {
+		c := NewLocalCodebase(dir, WithMaxFiles(4))
+		files, _ := c.files()
+		if len(files) != 4 || !c.Truncated() {
+			t.Errorf("got %v (truncated %v), expected 4 files and truncated", files, c.Truncated())
+		}
+		if c := NewLocalCodebase(dir); c.Truncated() {
+			t.Errorf("got truncated, expected all files to be listed")
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		c := NewLocalCodebase(dir, WithMaxDepth(1))
+		if _, err := c.FindFile("c.txt"); err != nil {
+			t.Errorf("got error %v, expected sub to be scanned", err)
+		}
+		if found, err := c.FindFile("d.txt"); err == nil {
+			t.Errorf("got %q, expected sub/sub not to be scanned", found)
+		}
+	})
+
+	t.Run("max depth 0", func(t *testing.T) {
+		files, _ := NewLocalCodebase(dir, WithMaxDepth(0)).files()
+		if d := cmp.Diff([]string{".", "a.txt", "b.txt", "link.txt"}, files); d != "" {
+			t.Errorf("files mismatch (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("symlinks", func(t *testing.T) {
+		if _, err := NewLocalCodebase(dir).FindFile("link.txt"); err != nil {
+			t.Errorf("got error %v, expected symlinks to be listed by default", err)
+		}
+		if found, err := NewLocalCodebase(dir, WithSymlinks(SkipSymlinks)).FindFile("link.txt"); err == nil {
+			t.Errorf("got %q, expected symlinks to be skipped", found)
+		}
+	})
+}
+
+func TestLocalCodebase_followSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	shared := t.TempDir()
+	writeFiles(t, dir, map[string]string{"go.mod": "", "web/.gitkeep": ""})
+	writeFiles(t, shared, map[string]string{"package.json": "{}", "lib/lib.go": ""})
+	links := map[string]string{
+		"web/app":     shared,
+		"web/app2":    shared,                     // followed once already
+		"web/loop":    dir,                        // a dir the symlink is in
+		"web/missing": filepath.Join(dir, "nope"), // broken
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := NewLocalCodebase(dir, WithSymlinks(FollowSymlinks)).files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		".", "go.mod", "web", "web/.gitkeep", "web/app", "web/app2", "web/loop", "web/missing",
+		"web/app/lib", "web/app/package.json", "web/app/lib/lib.go",
+	}
+	if d := cmp.Diff(expected, files); d != "" {
+		t.Errorf("files mismatch (-want +got):\n%s", d)
+	}
+
+	files, _ = NewLocalCodebase(dir).files()
+	if d := cmp.Diff(expected[:8], files); d != "" {
+		t.Errorf("expected symlinked dirs not to be followed by default (-want +got):\n%s", d)
+	}
+}
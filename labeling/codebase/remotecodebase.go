@@ -0,0 +1,307 @@
+package codebase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// remoteBatchSize is how many files RemoteCodebase.Prefetch fetches per request
+const remoteBatchSize = 100
+
+// RemoteCodebase is a Codebase for files served over HTTP, so that a codebase can be
+// labeled without cloning it. The server at BaseURL must implement:
+//
+//   - GET {BaseURL}/tree, listing all files and dirs as JSON, like
+//     {"tree": [{"path": "go.mod", "type": "blob", "size": 42}, {"path": "cmd", "type": "tree"}]}
+//   - GET {BaseURL}/raw/{path}, returning the contents of a file, or 404 if there's none
+//   - POST {BaseURL}/batch, with {"paths": ["go.mod", "go.sum"]}, returning the contents of
+//     several files at once, base64 encoded, like {"files": {"go.mod": "bW9kdWxl..."}},
+//     leaving out files that don't exist
+//
+// The tree is fetched once, the first time it's needed, and shared by all rules. Files are
+// fetched when they are first read, unless Prefetch fetched them already. Like
+// LocalCodebase, files deeper than defaultMaxDepth and after the first maxFiles are left
+// out. See the remotetest package for a server to test with.
+type RemoteCodebase struct {
+	BaseURL string
+	Client  *http.Client
+
+	listOnce sync.Once
+	fileSet  []string
+	entries  map[string]remoteEntry
+	listErr  error
+
+	mu    sync.Mutex
+	files map[string]*remoteFile
+}
+
+// RemoteTree is the response of the tree endpoint of a RemoteCodebase server
+type RemoteTree struct {
+	Tree []RemoteTreeEntry `json:"tree"`
+}
+
+// RemoteTreeEntry is a file ("blob") or dir ("tree") in a RemoteTree
+type RemoteTreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// RemoteBatchRequest is the request body of the batch endpoint of a RemoteCodebase server
+type RemoteBatchRequest struct {
+	Paths []string `json:"paths"`
+}
+
+// RemoteBatchResponse is the response of the batch endpoint of a RemoteCodebase server
+type RemoteBatchResponse struct {
+	Files map[string][]byte `json:"files"`
+}
+
+type remoteEntry struct {
+	isDir bool
+	size  int64
+}
+
+// remoteFile is a file that is being fetched, or was fetched already: done is closed once
+// contents and err are set
+type remoteFile struct {
+	done     chan struct{}
+	contents []byte
+	err      error
+}
+
+// NewRemoteCodebase returns a RemoteCodebase for the server at baseURL. If client is nil,
+// http.DefaultClient is used.
+func NewRemoteCodebase(baseURL string, client *http.Client) *RemoteCodebase {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &RemoteCodebase{BaseURL: strings.TrimSuffix(baseURL, "/"), Client: client}
+}
+
+func (c *RemoteCodebase) list() ([]string, error) {
+	c.listOnce.Do(func() {
+		c.entries = make(map[string]remoteEntry)
+		resp, err := c.Client.Get(c.BaseURL + "/tree")
+		if err != nil {
+			c.listErr = fmt.Errorf("listing files: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			c.listErr = fmt.Errorf("listing files: %s", resp.Status)
+			return
+		}
+
+		var tree RemoteTree
+		if err = json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+			c.listErr = fmt.Errorf("listing files: %w", err)
+			return
+		}
+		for _, e := range tree.Tree {
+			p := path.Clean(e.Path)
+			isDir := e.Type == "tree"
+			if pathDepth(path.Dir(p)) > defaultMaxDepth || (isDir && pathDepth(p) > defaultMaxDepth) {
+				continue
+			}
+			c.fileSet = append(c.fileSet, p)
+			c.entries[p] = remoteEntry{isDir: isDir, size: e.Size}
+			if len(c.fileSet) >= maxFiles {
+				break
+			}
+		}
+		sort.Strings(c.fileSet)
+		sortByDepth(c.fileSet)
+	})
+	return c.fileSet, c.listErr
+}
+
+func (c *RemoteCodebase) FindFileMatching(
+	predicate func(string) bool,
+	glob ...string,
+) (string, error) {
+	return findFileMatching(c.Walk, predicate, glob...)
+}
+
+func (c *RemoteCodebase) FindFile(glob ...string) (path string, err error) {
+	return c.FindFileMatching(func(string) bool { return true }, glob...)
+}
+
+func (c *RemoteCodebase) FindAll(glob ...string) *Files {
+	return findAll(c.Walk, glob...)
+}
+
+func (c *RemoteCodebase) Walk(fn func(path string) error) error {
+	files, err := c.list()
+	if err != nil {
+		return err
+	}
+	return walkPaths(files, fn)
+}
+
+func (c *RemoteCodebase) Stat(filePath string) (FileInfo, error) {
+	if _, err := c.list(); err != nil {
+		return FileInfo{}, err
+	}
+	filePath = path.Clean(filePath)
+	if filePath == "." {
+		return FileInfo{Path: filePath, IsDir: true}, nil
+	}
+	e, ok := c.entries[filePath]
+	if !ok {
+		return FileInfo{}, NotFoundError
+	}
+	return FileInfo{Path: filePath, IsDir: e.isDir, Size: e.size}, nil
+}
+
+func (c *RemoteCodebase) ReadFile(filePath string) (contents []byte, err error) {
+	if _, err = c.list(); err != nil {
+		return nil, err
+	}
+	filePath = path.Clean(filePath)
+	if e, ok := c.entries[filePath]; !ok || e.isDir {
+		return nil, NotFoundError
+	}
+
+	f, fetch := c.startFetching([]string{filePath})
+	if len(fetch) > 0 {
+		f[0].contents, f[0].err = c.fetchRaw(filePath)
+		if f[0].err != nil && f[0].err != NotFoundError {
+			// the next read fetches it again
+			c.mu.Lock()
+			delete(c.files, filePath)
+			c.mu.Unlock()
+		}
+		close(f[0].done)
+	}
+	<-f[0].done
+	return f[0].contents, f[0].err
+}
+
+// Prefetch fetches the files matching a glob that haven't been fetched yet, in batches,
+// so that reading them later doesn't need a request per file
+func (c *RemoteCodebase) Prefetch(glob ...string) error {
+	found := c.FindAll(glob...)
+	if found.Err() != nil {
+		return found.Err()
+	}
+	var paths []string
+	for found.Next() {
+		if !c.entries[found.Path()].isDir {
+			paths = append(paths, found.Path())
+		}
+	}
+
+	files, fetch := c.startFetching(paths)
+	var err error
+	for start := 0; start < len(fetch); start += remoteBatchSize {
+		end := start + remoteBatchSize
+		if end > len(fetch) {
+			end = len(fetch)
+		}
+		batch := fetch[start:end]
+		batchPaths := make([]string, len(batch))
+		for i, b := range batch {
+			batchPaths[i] = paths[b]
+		}
+
+		contents, batchErr := c.fetchBatch(batchPaths)
+		if batchErr != nil {
+			err = batchErr
+			// ReadFile fetches them again
+			c.mu.Lock()
+			for _, p := range batchPaths {
+				delete(c.files, p)
+			}
+			c.mu.Unlock()
+		}
+		for _, b := range batch {
+			f := files[b]
+			f.contents, f.err = contents[paths[b]], batchErr
+			if batchErr == nil && f.contents == nil {
+				f.err = NotFoundError
+			} else if len(f.contents) > maxArchiveFileSize {
+				f.contents, f.err = nil, fmt.Errorf("%s: %w", paths[b], ErrFileTooLarge)
+			}
+			close(f.done)
+		}
+	}
+	return err
+}
+
+// startFetching returns the remoteFile of each path, and the indexes of the ones the caller
+// must fetch, as no one has started fetching them yet
+func (c *RemoteCodebase) startFetching(paths []string) (files []*remoteFile, fetch []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.files == nil {
+		c.files = make(map[string]*remoteFile)
+	}
+	for i, p := range paths {
+		f, ok := c.files[p]
+		if !ok {
+			f = &remoteFile{done: make(chan struct{})}
+			c.files[p] = f
+			fetch = append(fetch, i)
+		}
+		files = append(files, f)
+	}
+	return files, fetch
+}
+
+func (c *RemoteCodebase) fetchRaw(filePath string) ([]byte, error) {
+	segments := strings.Split(filePath, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	resp, err := c.Client.Get(c.BaseURL + "/raw/" + strings.Join(segments, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", filePath, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, NotFoundError
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", filePath, resp.Status)
+	}
+
+	contents, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", filePath, err)
+	}
+	if len(contents) > maxArchiveFileSize {
+		return nil, fmt.Errorf("%s: %w", filePath, ErrFileTooLarge)
+	}
+	return contents, nil
+}
+
+func (c *RemoteCodebase) fetchBatch(paths []string) (map[string][]byte, error) {
+	body, err := json.Marshal(RemoteBatchRequest{Paths: paths})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.Post(c.BaseURL+"/batch", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("fetching files: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching files: %s", resp.Status)
+	}
+
+	var batch RemoteBatchResponse
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxArchiveSize)).Decode(&batch)
+	if err != nil {
+		return nil, fmt.Errorf("fetching files: %w", err)
+	}
+	return batch.Files, nil
+}
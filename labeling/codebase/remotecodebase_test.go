@@ -0,0 +1,112 @@
+package codebase_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase/remotetest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRemoteCodebase(t *testing.T) {
+	files := map[string]string{
+		"package.json":          `{"name": "root"}`,
+		"web/package.json":      `{"name": "web"}`,
+		"web/src/index.ts":      "",
+		"docs/with space.md":    "# docs",
+		"a/b/c/d/too-deep.json": "{}",
+	}
+	for i := 0; i < 150; i++ {
+		files[fmt.Sprintf("data/%d.json", i)] = "{}"
+	}
+	server := remotetest.NewServer(files)
+	defer server.Close()
+
+	c := codebase.NewRemoteCodebase(server.URL, nil)
+
+	found := c.FindAll("package.json").Paths()
+	if d := cmp.Diff([]string{"package.json", "web/package.json"}, found); d != "" {
+		t.Errorf("FindAll() mismatch (-want +got):\n%s", d)
+	}
+	if p, err := c.FindFile("too-deep.json"); !errors.Is(err, codebase.NotFoundError) {
+		t.Errorf("got %q (error %v), expected files deeper than the max depth to be skipped", p, err)
+	}
+
+	// concurrent reads of the same file only fetch it once
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			contents, err := c.ReadFile("web/package.json")
+			if err != nil || string(contents) != `{"name": "web"}` {
+				t.Errorf("got %q (error %v)", contents, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	contents, err := c.ReadFile("docs/with space.md")
+	if err != nil || string(contents) != "# docs" {
+		t.Errorf("got %q (error %v)", contents, err)
+	}
+	if _, err = c.ReadFile("missing"); !errors.Is(err, codebase.NotFoundError) {
+		t.Errorf("got error %v, expected NotFoundError", err)
+	}
+
+	info, err := c.Stat("web/src")
+	if err != nil || !info.IsDir {
+		t.Errorf("got %+v (error %v), expected a dir", info, err)
+	}
+
+	// 150 files in two batches, plus package.json, which wasn't read yet
+	if err = c.Prefetch("*.json"); err != nil {
+		t.Fatal(err)
+	}
+	for p := range files {
+		if _, err = c.Stat(p); err == nil {
+			_, _ = c.ReadFile(p)
+		}
+	}
+
+	// files not in the tree, like missing, are not requested, and after Prefetch only
+	// web/src/index.ts is
+	expectedRequests := map[string]int{"tree": 1, "raw": 3, "batch": 2}
+	if d := cmp.Diff(expectedRequests, server.Requests()); d != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRemoteCodebase_serverError(t *testing.T) {
+	server := remotetest.NewServer(map[string]string{"go.mod": ""})
+	server.Close()
+
+	c := codebase.NewRemoteCodebase(server.URL, nil)
+	if _, err := c.FindFile("go.mod"); err == nil || errors.Is(err, codebase.NotFoundError) {
+		t.Errorf("got error %v, expected the request to fail", err)
+	}
+}
+
+func TestRemoteCodebase_Prefetch_tooLarge(t *testing.T) {
+	server := remotetest.NewServer(map[string]string{
+		"small.json": "{}",
+		// over the 16MiB limit of single files
+		"big.json": strings.Repeat(" ", 16<<20+1),
+	})
+	defer server.Close()
+
+	c := codebase.NewRemoteCodebase(server.URL, nil)
+	if err := c.Prefetch("*.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReadFile("big.json"); !errors.Is(err, codebase.ErrFileTooLarge) {
+		t.Errorf("got error %v, expected ErrFileTooLarge", err)
+	}
+	if contents, err := c.ReadFile("small.json"); err != nil || string(contents) != "{}" {
+		t.Errorf("got %q (error %v)", contents, err)
+	}
+}
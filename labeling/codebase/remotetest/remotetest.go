@@ -0,0 +1,109 @@
+// Package remotetest provides an HTTP server implementing the API codebase.RemoteCodebase
+// reads files from, for tests
+package remotetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+)
+
+// Server serves Files, a map of path to contents, and counts the requests it gets
+type Server struct {
+	*httptest.Server
+	Files map[string]string
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+// NewServer starts a Server for files. Close it when done.
+func NewServer(files map[string]string) *Server {
+	s := &Server{Files: files, requests: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tree", s.serveTree)
+	mux.HandleFunc("/raw/", s.serveRaw)
+	mux.HandleFunc("/batch", s.serveBatch)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Requests returns how many requests each endpoint got: "tree", "raw" and "batch"
+func (s *Server) Requests() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make(map[string]int)
+	for k, v := range s.requests {
+		requests[k] = v
+	}
+	return requests
+}
+
+func (s *Server) count(endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[endpoint]++
+}
+
+func (s *Server) serveTree(w http.ResponseWriter, r *http.Request) {
+	s.count("tree")
+
+	dirs := make(map[string]bool)
+	var tree codebase.RemoteTree
+	for p, contents := range s.Files {
+		tree.Tree = append(tree.Tree, codebase.RemoteTreeEntry{Path: p, Type: "blob", Size: int64(len(contents))})
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	for dir := range dirs {
+		tree.Tree = append(tree.Tree, codebase.RemoteTreeEntry{Path: dir, Type: "tree"})
+	}
+	sort.Slice(tree.Tree, func(i, j int) bool { return tree.Tree[i].Path < tree.Tree[j].Path })
+
+	writeJSON(w, tree)
+}
+
+func (s *Server) serveRaw(w http.ResponseWriter, r *http.Request) {
+	s.count("raw")
+
+	contents, ok := s.Files[strings.TrimPrefix(r.URL.Path, "/raw/")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = w.Write([]byte(contents))
+}
+
+func (s *Server) serveBatch(w http.ResponseWriter, r *http.Request) {
+	s.count("batch")
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req codebase.RemoteBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := codebase.RemoteBatchResponse{Files: make(map[string][]byte)}
+	for _, p := range req.Paths {
+		if contents, ok := s.Files[p]; ok {
+			resp.Files[p] = []byte(contents)
+		}
+	}
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
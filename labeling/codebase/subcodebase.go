@@ -0,0 +1,81 @@
+package codebase
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SubCodebase is a Codebase for the files under Dir in Parent, e.g. one of the projects
+// in a monorepo. Paths are relative to Dir. Files under any of the Exclude dirs (relative
+// to Parent, like Dir) are left out.
+type SubCodebase struct {
+	Parent  Codebase
+	Dir     string
+	Exclude []string
+}
+
+func (c SubCodebase) FindFileMatching(
+	predicate func(string) bool,
+	glob ...string,
+) (string, error) {
+	return findFileMatching(c.Walk, predicate, glob...)
+}
+
+func (c SubCodebase) FindAll(glob ...string) *Files {
+	return findAll(c.Walk, glob...)
+}
+
+func (c SubCodebase) Walk(fn func(path string) error) error {
+	return c.Parent.Walk(func(parentPath string) error {
+		path, ok := c.relPath(parentPath)
+		if !ok {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+func (c SubCodebase) Stat(path string) (FileInfo, error) {
+	parentPath := filepath.Join(c.Dir, path)
+	if _, ok := c.relPath(parentPath); !ok {
+		return FileInfo{}, NotFoundError
+	}
+	info, err := c.Parent.Stat(parentPath)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	info.Path = filepath.Clean(path)
+	return info, nil
+}
+
+// relPath returns parentPath relative to Dir, and false if it's not in this codebase
+func (c SubCodebase) relPath(parentPath string) (string, bool) {
+	for _, excluded := range c.Exclude {
+		if isInDir(parentPath, excluded) {
+			return "", false
+		}
+	}
+	if !isInDir(parentPath, c.Dir) {
+		return "", false
+	}
+	path, err := filepath.Rel(c.Dir, parentPath)
+	return path, err == nil
+}
+
+// isInDir returns true if path is dir, or a file or dir under it
+func isInDir(path string, dir string) bool {
+	dir = filepath.Clean(dir)
+	if dir == "." {
+		return true
+	}
+	path = filepath.Clean(path)
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+func (c SubCodebase) FindFile(glob ...string) (path string, err error) {
+	return c.FindFileMatching(func(string) bool { return true }, glob...)
+}
+
+func (c SubCodebase) ReadFile(path string) (contents []byte, err error) {
+	return c.Parent.ReadFile(filepath.Join(c.Dir, path))
+}
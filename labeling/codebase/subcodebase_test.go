@@ -0,0 +1,67 @@
+package codebase
+
+import "testing"
+
+func TestSubCodebase_FindFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		dir          string
+		exclude      []string
+		globs        []string
+		expectedPath string
+		expectErr    bool
+	}{
+		{
+			name:         "find.me found in testdata dir",
+			dir:          "testdata",
+			globs:        []string{"find.me"},
+			expectedPath: "find.me",
+		}, {
+			name:         "find.me found in root dir",
+			dir:          ".",
+			globs:        []string{"find.me"},
+			expectedPath: "testdata/find.me",
+		}, {
+			name:         "path globs are relative to dir",
+			dir:          "testdata",
+			globs:        []string{"testdata/find.me"},
+			expectedPath: "",
+			expectErr:    true,
+		}, {
+			name:      "files in excluded dirs are not found",
+			dir:       ".",
+			exclude:   []string{"testdata"},
+			globs:     []string{"find.me"},
+			expectErr: true,
+		}, {
+			name:      "files outside of dir are not found",
+			dir:       "testdata",
+			globs:     []string{"localcodebase.go"},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := SubCodebase{Parent: NewLocalCodebase(""), Dir: tt.dir, Exclude: tt.exclude}
+			gotPath, err := c.FindFile(tt.globs...)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("FindFile() error %v, expectErr %v", err, tt.expectErr)
+				return
+			}
+			if gotPath != tt.expectedPath {
+				t.Errorf(" got %q, expected %q", gotPath, tt.expectedPath)
+			}
+		})
+	}
+}
+
+func TestSubCodebase_ReadFile(t *testing.T) {
+	c := SubCodebase{Parent: NewLocalCodebase(""), Dir: "testdata"}
+	got, err := c.ReadFile("find.me")
+	if err != nil {
+		t.Errorf("ReadFile() error %v", err)
+	}
+	if string(got) != "test file for localcodebase tests" {
+		t.Errorf("got %q, expected contents of testdata/find.me", got)
+	}
+}
@@ -0,0 +1,124 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyCppRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "CMake with Conan, and the Makefile it generated",
+			files: map[string]string{
+				"CMakeLists.txt":     "cmake_minimum_required(VERSION 3.21)",
+				"src/CMakeLists.txt": "add_library(lib lib.cpp)",
+				"src/lib.cpp":        "",
+				"conanfile.txt":      "[requires]\nfmt/10.2.1",
+				"build/Makefile":     "test:\n\tctest",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key:       labels.BuildCMake,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+				{
+					Key:       labels.PackageManagerConan,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+			},
+		},
+		{
+			name: "Meson with vcpkg",
+			files: map[string]string{
+				"meson.build": "project('app', 'c')",
+				"vcpkg.json":  `{"dependencies": ["zlib"]}`,
+				"main.c":      "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key:       labels.BuildMeson,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+				{
+					Key:       labels.PackageManagerVcpkg,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+			},
+		},
+		{
+			name: "Makefile with a test target",
+			files: map[string]string{
+				"Makefile":   "all: app\n\ntest: app\n\t./run-tests.sh\n",
+				"src/main.c": "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key:       labels.BuildMake,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+			},
+		},
+		{
+			name: "Makefile without a test target",
+			files: map[string]string{
+				"Makefile":   "all: app\n",
+				"src/main.c": "",
+			},
+		},
+		{
+			name: "Makefile without C sources",
+			files: map[string]string{
+				"Makefile": "test:\n\t./run-tests.sh\n",
+			},
+		},
+		{
+			name: "Bazel workspace with a version",
+			files: map[string]string{
+				"MODULE.bazel":  `module(name = "app")`,
+				".bazelversion": "7.0.2\n",
+				"BUILD.bazel":   "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key:       labels.BuildBazel,
+					LabelData: labels.LabelData{BasePath: ".", Version: "7.0.2"},
+				},
+			},
+		},
+		{
+			name: "Bazel workspace without bzlmod",
+			files: map[string]string{
+				"build/WORKSPACE": "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key:       labels.BuildBazel,
+					LabelData: labels.LabelData{BasePath: "build"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyDenoAndBunRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "deno.jsonc with comments and a lock file",
+			files: map[string]string{
+				"deno.jsonc": denoJSONC,
+				"deno.lock":  "{}",
+				"main.ts":    "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsDeno,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						HasLockFile:  true,
+						Dependencies: map[string]string{"@std/assert": "jsr:@std/assert@^1.0.0"},
+						Tasks:        map[string]string{"dev": "deno run --watch main.ts"},
+					},
+				},
+			},
+		},
+		{
+			name: "deno.json in a subdir",
+			files: map[string]string{
+				"api/deno.json": `{"tasks": {"start": "deno run main.ts"}}`,
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsDeno,
+					LabelData: labels.LabelData{
+						BasePath: "api",
+						Tasks:    map[string]string{"start": "deno run main.ts"},
+					},
+				},
+			},
+		},
+		{
+			name: "bun lock file",
+			files: map[string]string{
+				"package.json": `{"scripts": {"build": "bun build ./index.ts --outdir dist"}}`,
+				"bun.lockb":    "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsNode,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						HasLockFile:  true,
+						Dependencies: map[string]string{},
+						Tasks:        map[string]string{"build": "bun build ./index.ts --outdir dist"},
+					},
+				},
+				{
+					Key:       labels.PackageManagerBun,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+			},
+		},
+		{
+			name: "bun lock file of fixtures",
+			files: map[string]string{
+				"package.json":            "{}",
+				"package-lock.json":       "{}",
+				"test/fixtures/bun.lockb": "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsNode,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						HasLockFile:  true,
+						Dependencies: map[string]string{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+const denoJSONC = `{
+  // run with deno task dev
+  "tasks": {
+    "dev": "deno run --watch main.ts" /* watches for changes */
+  },
+  "imports": {
+    "@std/assert": "jsr:@std/assert@^1.0.0"
+  }
+}
+`
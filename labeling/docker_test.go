@@ -0,0 +1,89 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyDockerRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "Dockerfile at the root",
+			files: map[string]string{
+				"Dockerfile":              "FROM scratch",
+				"Dockerfile.dockerignore": "*.log",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.ArtifactDockerImage,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Tasks:    map[string]string{"Dockerfile": "."},
+					},
+				},
+			},
+		},
+		{
+			name: "compose file with build contexts",
+			files: map[string]string{
+				"compose.yaml":        composeYAML,
+				"web/Dockerfile":      "FROM nginx",
+				"api/Dockerfile.prod": "FROM golang",
+				"tools/ci.Dockerfile": "FROM alpine",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.ArtifactDockerImage,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Tasks: map[string]string{
+							"web/Dockerfile":      "web",
+							"api/Dockerfile.prod": ".",
+							"tools/ci.Dockerfile": "tools",
+						},
+						Dependencies: map[string]string{
+							"web/Dockerfile":      "web",
+							"api/Dockerfile.prod": "api",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// composeYAML builds web in its dir, api from the root with another Dockerfile, and
+// pulls db, which has no Dockerfile
+const composeYAML = `services:
+  web:
+    build: ./web
+  api:
+    build:
+      context: .
+      dockerfile: api/Dockerfile.prod
+  db:
+    image: postgres:16
+`
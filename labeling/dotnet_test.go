@@ -0,0 +1,147 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyDotnetRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "solution with a test project",
+			files: map[string]string{
+				"Shop.sln":                        "",
+				"global.json":                     `{"sdk": {"version": "8.0.100", "rollForward": "latestFeature"}}`,
+				"src/Shop.Api/Shop.Api.csproj":    apiCsproj,
+				"src/Shop.Api/packages.lock.json": "{}",
+				"tests/Shop.Tests/Shop.Tests.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Microsoft.NET.Test.Sdk" Version="17.8.0" />
+    <PackageReference Include="xunit" Version="2.6.2" />
+  </ItemGroup>
+</Project>`,
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsDotnet,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Version:  "8.0.100",
+						Dependencies: map[string]string{
+							"Microsoft.EntityFrameworkCore": "8.0.0",
+							"Serilog":                       "",
+							"Microsoft.NET.Test.Sdk":        "17.8.0",
+							"xunit":                         "2.6.2",
+						},
+						HasLockFile: true,
+					},
+				},
+				{
+					Key:       labels.TestDotnet,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+				{
+					Key: labels.ArtifactDotnetExecutable,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Tasks:    map[string]string{"src/Shop.Api/Shop.Api.csproj": "Shop.Api"},
+					},
+				},
+			},
+		},
+		{
+			name: "MSTest meta-package, a console app and a library",
+			files: map[string]string{
+				"Tools.sln": "",
+				"Cli/Cli.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+  </PropertyGroup>
+</Project>`,
+				"Core/Core.csproj": `<Project Sdk="Microsoft.NET.Sdk"></Project>`,
+				"Core.Tests/Core.Tests.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="MSTest" Version="3.6.0" />
+  </ItemGroup>
+</Project>`,
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsDotnet,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						Dependencies: map[string]string{"MSTest": "3.6.0"},
+					},
+				},
+				{
+					Key:       labels.TestDotnet,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+				{
+					Key: labels.ArtifactDotnetExecutable,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Tasks:    map[string]string{"Cli/Cli.csproj": "Cli"},
+					},
+				},
+			},
+		},
+		{
+			name: "project without tests, versioned by its target frameworks",
+			files: map[string]string{
+				"app/App.fsproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFrameworks>net6.0;net48;net10.0</TargetFrameworks>
+  </PropertyGroup>
+</Project>`,
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsDotnet,
+					LabelData: labels.LabelData{
+						BasePath:     "app",
+						Version:      "10.0",
+						Dependencies: map[string]string{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+const apiCsproj = `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <Nullable>enable</Nullable>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Microsoft.EntityFrameworkCore" Version="8.0.0" />
+    <PackageReference Include="Serilog" />
+  </ItemGroup>
+</Project>`
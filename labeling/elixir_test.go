@@ -0,0 +1,144 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyElixirRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "phoenix app with ecto and postgres",
+			files: map[string]string{
+				"mix.exs":        phoenixMixExs,
+				"mix.lock":       "%{}",
+				".tool-versions": "elixir 1.15.7-otp-26\nnodejs 20.9.0\n",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsElixir,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Version:  "1.15.7",
+						Dependencies: map[string]string{
+							"phoenix":         "~> 1.7.10",
+							"ecto_sql":        "~> 3.10",
+							"postgrex":        ">= 0.0.0",
+							"junit_formatter": "~> 3.3",
+							"shared":          "true",
+							"erlang":          "26",
+						},
+						HasLockFile: true,
+					},
+				},
+			},
+		},
+		{
+			name: "mix project without a lock file",
+			files: map[string]string{
+				"lib/mix.exs": `defmodule Lib.MixProject do
+  def project do
+    [app: :lib, version: "0.1.0", elixir: "~> 1.14", deps: deps()]
+  end
+
+  defp deps do
+    []
+  end
+end`,
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsElixir,
+					LabelData: labels.LabelData{
+						BasePath:     "lib",
+						Version:      "1.14",
+						Dependencies: map[string]string{},
+					},
+				},
+			},
+		},
+		{
+			name: "rebar3 project",
+			files: map[string]string{
+				"rebar.config":   "{deps, [{cowboy, \"2.10.0\"}]}.",
+				"rebar.lock":     "[].",
+				".tool-versions": "erlang 26.1.2\n",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsErlang,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						Version:      "26.1.2",
+						Dependencies: map[string]string{"erlang": "26.1.2"},
+						HasLockFile:  true,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("rebar.config of a mix project", func(t *testing.T) {
+		c := codebase.MapCodebase(map[string]string{"mix.exs": phoenixMixExs, "rebar.config": ""})
+		if got := ApplyAllRules(c); got[labels.DepsErlang].Valid {
+			t.Errorf("got %v, expected no %s label", got, labels.DepsErlang)
+		}
+	})
+}
+
+const phoenixMixExs = `defmodule Shop.MixProject do
+  use Mix.Project
+
+  def project do
+    [
+      app: :shop,
+      version: "0.1.0",
+      elixir: "~> 1.14",
+      start_permanent: Mix.env() == :prod,
+      aliases: aliases(),
+      deps: deps()
+    ]
+  end
+
+  def application do
+    [mod: {Shop.Application, []}, extra_applications: [:logger]]
+  end
+
+  defp deps do
+    [
+      {:phoenix, "~> 1.7.10"},
+      {:ecto_sql, "~> 3.10"},
+      {:postgrex, ">= 0.0.0"},
+      {:junit_formatter, "~> 3.3", only: [:test]},
+      {:shared, in_umbrella: true}
+    ]
+  end
+
+  defp aliases do
+    [test: ["ecto.create --quiet", "ecto.migrate --quiet", "test"]]
+  end
+end
+`
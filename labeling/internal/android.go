@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"path"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// androidAppPlugins are the ways a Gradle build file can apply the Android application plugin
+var androidAppPlugins = []string{"com.android.application", "android.application", "androidApplication"}
+
+// kotlinMultiplatformPlugins are the ways a Gradle build file can apply the Kotlin
+// Multiplatform plugin
+var kotlinMultiplatformPlugins = []string{
+	`kotlin("multiplatform")`, "org.jetbrains.kotlin.multiplatform", "kotlin.multiplatform", "kotlinMultiplatform",
+}
+
+var AndroidRules = []labels.Rule{
+	{
+		ID:          "android/app",
+		Description: "Finds Gradle modules applying the Android application plugin",
+		Produces:    []string{labels.DepsAndroid},
+		DependsOn:   []string{labels.DepsJava},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsAndroid
+			err = findGradleModule(c, ls, androidAppPlugins, &label)
+			return label, err
+		},
+	},
+	{
+		ID:          "kotlin/multiplatform",
+		Description: "Finds Gradle modules applying the Kotlin Multiplatform plugin",
+		Produces:    []string{labels.DepsKotlinMultiplatform},
+		DependsOn:   []string{labels.DepsJava},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsKotlinMultiplatform
+			err = findGradleModule(c, ls, kotlinMultiplatformPlugins, &label)
+			return label, err
+		},
+	},
+}
+
+// findGradleModule makes label valid if the build file of a Gradle module applies any of
+// plugins. Its BasePath is the root of the Gradle build the module is in, where gradlew is.
+func findGradleModule(c codebase.Codebase, ls labels.LabelSet, plugins []string, label *labels.Label) error {
+	buildFiles := c.FindAll("build.gradle", "build.gradle.kts")
+	for buildFiles.Next() {
+		contents, err := c.ReadFile(buildFiles.Path())
+		if err != nil {
+			return err
+		}
+		if !containsAny(string(contents), plugins) {
+			continue
+		}
+
+		moduleDir := path.Dir(buildFiles.Path())
+		label.Valid = true
+		label.BasePath = moduleDir
+		if javaLabel := ls[labels.DepsJava]; javaLabel.Valid &&
+			isUnderAny(moduleDir, map[string]bool{javaLabel.BasePath: true}) {
+			label.BasePath = javaLabel.BasePath
+		}
+		label.AddFiles(buildFiles.Path())
+		return nil
+	}
+	return buildFiles.Err()
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
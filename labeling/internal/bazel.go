@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"path"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// bazelWorkspaceFiles mark the root of a Bazel workspace, with bzlmod or without
+var bazelWorkspaceFiles = []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"}
+
+// BazelRules label Bazel workspaces whatever the languages they build, as Bazel builds
+// and tests them all the same way
+var BazelRules = []labels.Rule{
+	{
+		ID:          "bazel/workspace",
+		Description: "Finds MODULE.bazel or WORKSPACE, and the Bazel version in .bazelversion",
+		Produces:    []string{labels.BuildBazel},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.BuildBazel
+			workspaceFile, err := c.FindFile(bazelWorkspaceFiles...)
+			label.Valid = workspaceFile != ""
+			if !label.Valid {
+				return label, err
+			}
+			label.BasePath = path.Dir(workspaceFile)
+			label.AddFiles(workspaceFile)
+
+			versionPath := path.Join(label.BasePath, ".bazelversion")
+			if contents, err := c.ReadFile(versionPath); err == nil {
+				label.Version = strings.TrimSpace(string(contents))
+				label.AddFiles(versionPath)
+			}
+			return label, nil
+		},
+	},
+}
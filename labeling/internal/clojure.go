@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"path"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+var ClojureRules = []labels.Rule{
+	{
+		ID:          "clojure/deps",
+		Description: "Finds a Leiningen project.clj or a tools.deps deps.edn",
+		Produces:    []string{labels.DepsClojure},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsClojure
+			depsFile, err := c.FindFile("project.clj", "deps.edn")
+			label.Valid = depsFile != ""
+			label.BasePath = path.Dir(depsFile)
+			label.AddFiles(depsFile)
+			return label, err
+		},
+	},
+	{
+		ID:          "clojure/leiningen",
+		Description: "Finds project.clj in the Clojure project dir",
+		Produces:    []string{labels.PackageManagerLein},
+		DependsOn:   []string{labels.DepsClojure},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.PackageManagerLein
+			if !ls[labels.DepsClojure].Valid {
+				return label, nil
+			}
+			projectClj := path.Join(ls[labels.DepsClojure].BasePath, "project.clj")
+			label.Valid = hasFile(c, projectClj)
+			if label.Valid {
+				label.BasePath = ls[labels.DepsClojure].BasePath
+				label.AddFiles(projectClj)
+			}
+			return label, nil
+		},
+	},
+	{
+		ID:          "clojure/uberjar",
+		Description: "Finds a :main namespace or an :uberjar profile in project.clj",
+		Produces:    []string{labels.ArtifactLeinUberjar},
+		DependsOn:   []string{labels.PackageManagerLein},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.ArtifactLeinUberjar
+			if !ls[labels.PackageManagerLein].Valid {
+				return label, nil
+			}
+			projectClj := path.Join(ls[labels.PackageManagerLein].BasePath, "project.clj")
+			contents, err := c.ReadFile(projectClj)
+			if err != nil {
+				return label, err
+			}
+			label.Valid = strings.Contains(string(contents), ":main") ||
+				strings.Contains(string(contents), ":uberjar")
+			label.BasePath = ls[labels.PackageManagerLein].BasePath
+			label.AddFiles(projectClj)
+			return label, nil
+		},
+	},
+}
@@ -1,8 +1,120 @@
 package internal
 
-import "github.com/CircleCI-Public/circleci-config/labeling/codebase"
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+)
 
 func hasPath(c codebase.Codebase, path string) bool {
 	foundPath, _ := c.FindFile(path)
 	return foundPath != ""
 }
+
+// hasFile returns true if there is a file at exactly filePath, unlike hasPath, which
+// matches a single-segment path anywhere in the codebase
+func hasFile(c codebase.Codebase, filePath string) bool {
+	info, err := c.Stat(filePath)
+	return err == nil && !info.IsDir
+}
+
+// projectManifests are the files that mark the root dir of a project, grouped by stack
+var projectManifests = [][]string{
+	{"go.mod"},
+	{"package.json"},
+	denoConfigFiles,
+	{"pom.xml", "gradlew"},
+	{"Gemfile", "*.gemspec"},
+	{"Cargo.toml", "cargo.toml"},
+	{"composer.json"},
+	dotnetProjectFiles,
+	{"mix.exs", "rebar.config"},
+	{"Package.swift", "Podfile"},
+	{"build.sbt"},
+	{"project.clj", "deps.edn"},
+	{"CMakeLists.txt", "meson.build"},
+	bazelWorkspaceFiles,
+	possiblePythonFiles,
+}
+
+// configFiles are the files, besides projectManifests, that rules read, like lint and
+// version configs. Lock files are left out, as rules only check if they exist.
+var configFiles = []string{
+	".bazelversion", ".python-version", ".tool-versions", ".xcode-version", "global.json",
+	".yarnrc.yml", ".yarnrc.yaml", "pnpm-workspace.yaml", "turbo.json", "nx.json", "lerna.json",
+	"setup.cfg", "tox.ini", "conanfile.txt", "vcpkg.json", "build.gradle", "build.gradle.kts",
+	".gitlab-ci.yml", "compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml",
+}
+
+// ReadFiles returns the globs of the files rules read, so that codebases fetching files
+// remotely can fetch them all upfront
+func ReadFiles() []string {
+	var globs []string
+	for _, manifests := range projectManifests {
+		globs = append(globs, manifests...)
+	}
+	return append(globs, configFiles...)
+}
+
+// ProjectRoots returns the dirs with a project manifest, like go.mod or package.json,
+// shallowest first. Manifests under the root of a project of the same stack are taken to
+// be part of it (e.g. the package.json files of its node_modules), not projects of their own.
+func ProjectRoots(c codebase.Codebase) []string {
+	isRoot := make(map[string]bool)
+	for _, manifests := range projectManifests {
+		var dirs []string
+		files := c.FindAll(manifests...)
+		for files.Next() {
+			dirs = append(dirs, path.Dir(files.Path()))
+		}
+		// shallowest first, so the root of a project is found before anything under it
+		sortDirs(dirs)
+
+		stackRoots := make(map[string]bool)
+		for _, dir := range dirs {
+			if !isUnderAny(dir, stackRoots) {
+				stackRoots[dir] = true
+				isRoot[dir] = true
+			}
+		}
+	}
+
+	roots := make([]string, 0, len(isRoot))
+	for dir := range isRoot {
+		roots = append(roots, dir)
+	}
+	sortDirs(roots)
+	return roots
+}
+
+// sortDirs sorts dirs by depth, then by name
+func sortDirs(dirs []string) {
+	sort.Slice(dirs, func(i, j int) bool {
+		di, dj := dirDepth(dirs[i]), dirDepth(dirs[j])
+		if di != dj {
+			return di < dj
+		}
+		return dirs[i] < dirs[j]
+	})
+}
+
+// isUnderAny returns true if dir is one of dirs, or a subdir of one of them
+func isUnderAny(dir string, dirs map[string]bool) bool {
+	for d := dir; ; d = path.Dir(d) {
+		if dirs[d] {
+			return true
+		}
+		if d == "." || d == "/" {
+			return false
+		}
+	}
+}
+
+func dirDepth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"errors"
+	"path"
+	"regexp"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// cppSources are the globs of C and C++ source and header files
+var cppSources = []string{"*.{c,cc,cpp,cxx,h,hh,hpp}"}
+
+var makeTestTargetPattern = regexp.MustCompile(`(?m)^test\s*:`)
+
+var CppRules = []labels.Rule{
+	{
+		ID:          "cpp/cmake",
+		Description: "Finds CMakeLists.txt",
+		Produces:    []string{labels.BuildCMake},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.BuildCMake
+			cmakeLists, err := c.FindFile("CMakeLists.txt")
+			label.Valid = cmakeLists != ""
+			label.BasePath = path.Dir(cmakeLists)
+			label.AddFiles(cmakeLists)
+			return label, err
+		},
+	},
+	{
+		ID:          "cpp/meson",
+		Description: "Finds meson.build",
+		Produces:    []string{labels.BuildMeson},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.BuildMeson
+			mesonBuild, err := c.FindFile("meson.build")
+			label.Valid = mesonBuild != ""
+			label.BasePath = path.Dir(mesonBuild)
+			label.AddFiles(mesonBuild)
+			return label, err
+		},
+	},
+	{
+		// Makefiles are used by all kinds of projects, so only those next to C or C++
+		// sources, with a test target, and not generated by CMake or Meson, are labeled
+		ID:          "cpp/make",
+		Description: "Finds a Makefile with a test target in a C or C++ project",
+		Produces:    []string{labels.BuildMake},
+		DependsOn:   []string{labels.BuildCMake, labels.BuildMeson},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.BuildMake
+			if ls[labels.BuildCMake].Valid || ls[labels.BuildMeson].Valid {
+				return label, nil
+			}
+			makefile, err := c.FindFile("Makefile", "makefile", "GNUmakefile")
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+			source, err := c.FindFile(cppSources...)
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+			contents, err := c.ReadFile(makefile)
+			if err != nil {
+				return label, err
+			}
+			label.Valid = makeTestTargetPattern.Match(contents)
+			label.BasePath = path.Dir(makefile)
+			label.AddFiles(makefile, source)
+			return label, nil
+		},
+	},
+	{
+		ID:          "cpp/conan",
+		Description: "Finds a conanfile.txt or conanfile.py",
+		Produces:    []string{labels.PackageManagerConan},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.PackageManagerConan
+			conanfile, err := c.FindFile("conanfile.txt", "conanfile.py")
+			label.Valid = conanfile != ""
+			label.BasePath = path.Dir(conanfile)
+			label.AddFiles(conanfile)
+			return label, err
+		},
+	},
+	{
+		ID:          "cpp/vcpkg",
+		Description: "Finds a vcpkg.json manifest",
+		Produces:    []string{labels.PackageManagerVcpkg},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.PackageManagerVcpkg
+			manifest, err := c.FindFile("vcpkg.json")
+			label.Valid = manifest != ""
+			label.BasePath = path.Dir(manifest)
+			label.AddFiles(manifest)
+			return label, err
+		},
+	},
+}
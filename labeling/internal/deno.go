@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"encoding/json"
+	"path"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+var denoConfigFiles = []string{"deno.json", "deno.jsonc"}
+
+var DenoRules = []labels.Rule{
+	{
+		ID:          "deno/deps",
+		Description: "Finds deno.json or deno.jsonc and reads its imports and tasks",
+		Produces:    []string{labels.DepsDeno},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsDeno
+			configPath, _ := c.FindFile(denoConfigFiles...)
+			label.Valid = configPath != ""
+			if !label.Valid {
+				return label, nil
+			}
+			label.BasePath = path.Dir(configPath)
+
+			contents, err := c.ReadFile(configPath)
+			if err != nil {
+				return label, err
+			}
+			var config denoConfig
+			if err = json.Unmarshal(stripJSONComments(contents), &config); err != nil {
+				return label, err
+			}
+			label.Dependencies = config.Imports
+			label.Tasks = config.Tasks
+
+			lockPath := path.Join(label.BasePath, "deno.lock")
+			label.HasLockFile = hasFile(c, lockPath)
+			if label.HasLockFile {
+				label.AddFiles(lockPath)
+			}
+			label.AddFiles(configPath)
+			return label, nil
+		},
+	},
+}
+
+// denoConfig for unmarshalling deno.json files
+type denoConfig struct {
+	Imports map[string]string `json:"imports"`
+	Tasks   map[string]string `json:"tasks"`
+}
+
+// stripJSONComments removes the // and /* */ comments of JSONC, like deno.jsonc, leaving
+// strings as they are
+func stripJSONComments(contents []byte) []byte {
+	stripped := make([]byte, 0, len(contents))
+	inString := false
+	for i := 0; i < len(contents); i++ {
+		ch := contents[i]
+		switch {
+		case inString:
+			stripped = append(stripped, ch)
+			if ch == '\\' && i+1 < len(contents) {
+				i++
+				stripped = append(stripped, contents[i])
+			} else if ch == '"' {
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+			stripped = append(stripped, ch)
+		case ch == '/' && i+1 < len(contents) && contents[i+1] == '/':
+			for i < len(contents) && contents[i] != '\n' {
+				i++
+			}
+			if i < len(contents) {
+				stripped = append(stripped, '\n')
+			}
+		case ch == '/' && i+1 < len(contents) && contents[i+1] == '*':
+			i += 2
+			for i+1 < len(contents) && !(contents[i] == '*' && contents[i+1] == '/') {
+				i++
+			}
+			i++
+		default:
+			stripped = append(stripped, ch)
+		}
+	}
+	return stripped
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"path"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// dockerfiles are named Dockerfile, or have it as prefix or suffix, like Dockerfile.dev,
+// but Dockerfile.dockerignore files are the ignore files of the Dockerfile next to them
+var dockerfiles = []string{"Dockerfile", "*.Dockerfile", "Dockerfile.*", "!*.dockerignore"}
+
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+var DockerRules = []labels.Rule{
+	{
+		ID: "docker/image",
+		Description: "Finds Dockerfiles, and the build contexts and image names of the services " +
+			"in compose files building them",
+		Produces: []string{labels.ArtifactDockerImage},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.ArtifactDockerImage
+			label.BasePath = "."
+			// Tasks maps each Dockerfile to the context it's built in, and Dependencies to
+			// the name of the compose service building it, if any. Their paths are
+			// relative to BasePath.
+			label.Tasks = make(map[string]string)
+			label.Dependencies = make(map[string]string)
+
+			found := c.FindAll(dockerfiles...)
+			for found.Next() {
+				label.Tasks[found.Path()] = path.Dir(found.Path())
+				label.AddFiles(found.Path())
+			}
+			if found.Err() != nil {
+				return label, found.Err()
+			}
+
+			found = c.FindAll(composeFiles...)
+			for found.Next() {
+				compose, err := codebase.ReadYAML[composeFile](c, found.Path())
+				if err != nil {
+					// not every YAML file named like this is a compose file we can read
+					continue
+				}
+				for name, service := range compose.Services {
+					dockerfile, context := service.dockerfile(path.Dir(found.Path()))
+					if dockerfile == "" || !hasFile(c, dockerfile) {
+						continue
+					}
+					label.Tasks[dockerfile] = context
+					label.Dependencies[dockerfile] = name
+					label.AddFiles(dockerfile, found.Path())
+				}
+			}
+
+			label.Valid = len(label.Tasks) > 0
+			if len(label.Dependencies) == 0 {
+				label.Dependencies = nil
+			}
+			return label, found.Err()
+		},
+	},
+}
+
+// composeFile for unmarshalling the services of docker-compose.yml files
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	// Build is the path of the build context, or a map with "context" and "dockerfile"
+	Build interface{} `yaml:"build"`
+}
+
+// dockerfile returns the path of the Dockerfile a service in a compose file in dir is built
+// from, and its build context, or "" for services that use an image or a remote context
+func (s composeService) dockerfile(dir string) (dockerfile string, context string) {
+	context, dockerfile = ".", "Dockerfile"
+	switch build := s.Build.(type) {
+	case string:
+		context = build
+	case map[string]interface{}:
+		if c, ok := build["context"].(string); ok {
+			context = c
+		}
+		if d, ok := build["dockerfile"].(string); ok {
+			dockerfile = d
+		}
+	default:
+		return "", ""
+	}
+	if strings.Contains(context, "://") || strings.HasPrefix(context, "git@") {
+		return "", ""
+	}
+	context = path.Join(dir, context)
+	dockerfile = path.Join(context, dockerfile)
+	if strings.HasPrefix(context, "..") || strings.HasPrefix(dockerfile, "..") {
+		return "", ""
+	}
+	return dockerfile, context
+}
@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// dotnetProjectFiles are the solution and project files of .NET codebases, solutions first
+var dotnetProjectFiles = []string{"*.sln", "*.csproj", "*.fsproj"}
+
+// dotnetTestPackages are the packages referenced by test projects of each test framework
+var dotnetTestPackages = []string{"xunit", "NUnit", "MSTest", "MSTest.TestFramework"}
+
+// dotnetExecutableSdks are the MSBuild SDKs of projects that are executables unless their
+// OutputType says otherwise
+var dotnetExecutableSdks = []string{"Microsoft.NET.Sdk.Web", "Microsoft.NET.Sdk.Worker"}
+
+var DotnetRules = []labels.Rule{
+	{
+		ID:          "dotnet/deps",
+		Description: "Finds .sln, .csproj or .fsproj files, their package references and the SDK version",
+		Produces:    []string{labels.DepsDotnet},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsDotnet
+			projectFile, err := findDotnetProjectFile(c)
+			label.Valid = projectFile != ""
+			if !label.Valid {
+				return label, err
+			}
+			label.BasePath = path.Dir(projectFile)
+			label.AddFiles(projectFile)
+
+			label.Dependencies = make(map[string]string)
+			projects := c.FindAll("*.csproj", "*.fsproj")
+			for projects.Next() {
+				project, err := codebase.ReadXML[msbuildProject](c, projects.Path())
+				if err != nil {
+					return label, err
+				}
+				for _, ref := range project.packageReferences() {
+					label.Dependencies[ref.Include] = ref.Version
+				}
+				if label.Version == "" {
+					label.Version = project.frameworkVersion()
+				}
+			}
+			if err = projects.Err(); err != nil {
+				return label, err
+			}
+
+			globalJSON, _ := c.FindFile("global.json")
+			if globalJSON != "" {
+				global, err := codebase.ReadJSON[dotnetGlobalJSON](c, globalJSON)
+				if err != nil {
+					return label, err
+				}
+				if global.SDK.Version != "" {
+					label.Version = global.SDK.Version
+				}
+				label.AddFiles(globalJSON)
+			}
+
+			lockFile, _ := c.FindFile("packages.lock.json")
+			label.HasLockFile = lockFile != ""
+			label.AddFiles(lockFile)
+			return label, nil
+		},
+	},
+	{
+		ID:          "dotnet/test",
+		Description: "Finds xunit, NUnit or MSTest in the .NET package references",
+		Produces:    []string{labels.TestDotnet},
+		DependsOn:   []string{labels.DepsDotnet},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.TestDotnet
+			depsLabel := ls[labels.DepsDotnet]
+			for _, pkg := range dotnetTestPackages {
+				if _, ok := depsLabel.Dependencies[pkg]; ok {
+					label.Evidence.Dependencies = append(label.Evidence.Dependencies, pkg)
+				}
+			}
+			label.Valid = len(label.Evidence.Dependencies) > 0
+			label.BasePath = depsLabel.BasePath
+			return label, nil
+		},
+	},
+	{
+		ID:          "dotnet/executable",
+		Description: "Finds the .NET projects that build executables, like console and web apps",
+		Produces:    []string{labels.ArtifactDotnetExecutable},
+		DependsOn:   []string{labels.DepsDotnet},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.ArtifactDotnetExecutable
+			depsLabel := ls[labels.DepsDotnet]
+			if !depsLabel.Valid {
+				return label, nil
+			}
+			label.BasePath = depsLabel.BasePath
+			// Tasks maps the path of each executable project, relative to BasePath, to
+			// its name
+			label.Tasks = make(map[string]string)
+
+			projects := c.FindAll("*.csproj", "*.fsproj")
+			for projects.Next() {
+				project, err := codebase.ReadXML[msbuildProject](c, projects.Path())
+				if err != nil {
+					return label, err
+				}
+				if !project.isExecutable() {
+					continue
+				}
+				relPath, err := filepath.Rel(label.BasePath, projects.Path())
+				if err != nil {
+					return label, err
+				}
+				name := strings.TrimSuffix(path.Base(projects.Path()), path.Ext(projects.Path()))
+				label.Tasks[filepath.ToSlash(relPath)] = name
+				label.AddFiles(projects.Path())
+			}
+			label.Valid = len(label.Tasks) > 0
+			return label, projects.Err()
+		},
+	},
+}
+
+// findDotnetProjectFile returns the shallowest solution file or, if there is none, the
+// shallowest project file
+func findDotnetProjectFile(c codebase.Codebase) (string, error) {
+	solution, err := c.FindFile("*.sln")
+	if solution != "" || !errors.Is(err, codebase.NotFoundError) {
+		return solution, err
+	}
+	return c.FindFile("*.csproj", "*.fsproj")
+}
+
+// msbuildProject for unmarshalling .csproj and .fsproj files
+type msbuildProject struct {
+	Sdk            string `xml:"Sdk,attr"`
+	PropertyGroups []struct {
+		TargetFramework  string `xml:"TargetFramework"`
+		TargetFrameworks string `xml:"TargetFrameworks"`
+		OutputType       string `xml:"OutputType"`
+	} `xml:"PropertyGroup"`
+	ItemGroups []struct {
+		PackageReferences []msbuildPackageReference `xml:"PackageReference"`
+	} `xml:"ItemGroup"`
+}
+
+type msbuildPackageReference struct {
+	Include string `xml:"Include,attr"`
+	Version string `xml:"Version,attr"`
+}
+
+func (p msbuildProject) packageReferences() []msbuildPackageReference {
+	var refs []msbuildPackageReference
+	for _, group := range p.ItemGroups {
+		refs = append(refs, group.PackageReferences...)
+	}
+	return refs
+}
+
+// isExecutable returns true for projects with an Exe or WinExe OutputType, or with the SDK of
+// web apps or workers and no OutputType. Test projects are never executables.
+func (p msbuildProject) isExecutable() bool {
+	for _, ref := range p.packageReferences() {
+		for _, pkg := range dotnetTestPackages {
+			if ref.Include == pkg {
+				return false
+			}
+		}
+	}
+	for _, group := range p.PropertyGroups {
+		if outputType := strings.TrimSpace(group.OutputType); outputType != "" {
+			return strings.EqualFold(outputType, "Exe") || strings.EqualFold(outputType, "WinExe")
+		}
+	}
+	for _, sdk := range dotnetExecutableSdks {
+		if p.Sdk == sdk {
+			return true
+		}
+	}
+	return false
+}
+
+var targetFrameworkVersion = regexp.MustCompile(`^net(\d+)\.(\d+)$`)
+
+// frameworkVersion returns the highest version of .NET the project targets, like "8.0"
+// for net8.0, ignoring .NET Framework and .NET Standard targets
+func (p msbuildProject) frameworkVersion() string {
+	var version string
+	var major, minor int
+	for _, group := range p.PropertyGroups {
+		frameworks := strings.Split(group.TargetFrameworks, ";")
+		frameworks = append(frameworks, group.TargetFramework)
+		for _, f := range frameworks {
+			m := targetFrameworkVersion.FindStringSubmatch(strings.TrimSpace(f))
+			if m == nil {
+				continue
+			}
+			fMajor, _ := strconv.Atoi(m[1])
+			fMinor, _ := strconv.Atoi(m[2])
+			if version == "" || fMajor > major || (fMajor == major && fMinor > minor) {
+				version, major, minor = m[1]+"."+m[2], fMajor, fMinor
+			}
+		}
+	}
+	return version
+}
+
+// dotnetGlobalJSON for unmarshalling global.json files, which pin the .NET SDK version
+type dotnetGlobalJSON struct {
+	SDK struct {
+		Version string `json:"version"`
+	} `json:"sdk"`
+}
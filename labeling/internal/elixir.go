@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// mixFrameworkDeps are the mix dependencies that change the jobs generated for a project,
+// recorded as evidence when found
+var mixFrameworkDeps = []string{"phoenix", "ecto_sql", "postgrex", "junit_formatter"}
+
+var ElixirRules = []labels.Rule{
+	{
+		ID:          "elixir/deps",
+		Description: "Finds mix.exs and reads its dependencies, and the Elixir and OTP versions",
+		Produces:    []string{labels.DepsElixir},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsElixir
+			mixPath, err := c.FindFile("mix.exs")
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+			contents, err := c.ReadFile(mixPath)
+			if err != nil {
+				return label, err
+			}
+			label.Valid = true
+			label.BasePath = path.Dir(mixPath)
+			label.Dependencies = parseMixDeps(string(contents))
+			label.Version = mixElixirVersion(string(contents))
+
+			toolVersionsPath, err := readToolVersions(c, &label)
+			if err != nil {
+				return label, err
+			}
+
+			lockPath := path.Join(label.BasePath, "mix.lock")
+			label.HasLockFile = hasFile(c, lockPath)
+			label.AddFiles(mixPath, toolVersionsPath)
+			if label.HasLockFile {
+				label.AddFiles(lockPath)
+			}
+			for _, dep := range mixFrameworkDeps {
+				if label.Dependencies[dep] != "" {
+					label.Evidence.Dependencies = append(label.Evidence.Dependencies, dep)
+				}
+			}
+			return label, nil
+		},
+	},
+	{
+		ID:          "erlang/deps",
+		Description: "Finds rebar.config in projects without mix.exs, and the OTP version",
+		Produces:    []string{labels.DepsErlang},
+		DependsOn:   []string{labels.DepsElixir},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsErlang
+			rebarPath, err := c.FindFile("rebar.config")
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+			// mix builds the rebar3 dependencies of Elixir projects
+			if ls[labels.DepsElixir].Valid && ls[labels.DepsElixir].BasePath == path.Dir(rebarPath) {
+				return label, nil
+			}
+			label.Valid = true
+			label.BasePath = path.Dir(rebarPath)
+
+			toolVersionsPath, err := readToolVersions(c, &label)
+			if err != nil {
+				return label, err
+			}
+			label.Version = label.Dependencies["erlang"]
+
+			lockPath := path.Join(label.BasePath, "rebar.lock")
+			label.HasLockFile = hasFile(c, lockPath)
+			label.AddFiles(rebarPath, toolVersionsPath)
+			if label.HasLockFile {
+				label.AddFiles(lockPath)
+			}
+			return label, nil
+		},
+	},
+}
+
+var mixDepPattern = regexp.MustCompile(`\{\s*:(\w+)\s*,\s*(?:"([^"]*)")?`)
+var mixFunctionPattern = regexp.MustCompile(`\n\s*defp? `)
+var mixElixirPattern = regexp.MustCompile(`elixir:\s*"([^"]*)"`)
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// parseMixDeps returns the dependencies in a mix.exs file, with their version requirement,
+// or "true" for dependencies without one, like {:dep, path: "../dep"}
+func parseMixDeps(mixExs string) map[string]string {
+	deps := make(map[string]string)
+	_, depsList, ok := strings.Cut(mixExs, "defp deps")
+	if !ok {
+		return deps
+	}
+	// up to the next function
+	if next := mixFunctionPattern.FindStringIndex(depsList); next != nil {
+		depsList = depsList[:next[0]]
+	}
+	for _, m := range mixDepPattern.FindAllStringSubmatch(depsList, -1) {
+		deps[m[1]] = m[2]
+		if m[2] == "" {
+			deps[m[1]] = "true"
+		}
+	}
+	return deps
+}
+
+// mixElixirVersion returns the version in the Elixir requirement of a mix.exs file, like
+// "1.14" for `elixir: "~> 1.14"`
+func mixElixirVersion(mixExs string) string {
+	m := mixElixirPattern.FindStringSubmatch(mixExs)
+	if m == nil {
+		return ""
+	}
+	return versionPattern.FindString(m[1])
+}
+
+// readToolVersions reads the versions in the asdf .tool-versions file of the project, or
+// of the codebase, into label: the Elixir version into Version, and the OTP version into
+// Dependencies["erlang"]. It returns the path of the file, if there is one.
+func readToolVersions(c codebase.Codebase, label *labels.Label) (string, error) {
+	toolVersionsPath := path.Join(label.BasePath, ".tool-versions")
+	if !hasFile(c, toolVersionsPath) {
+		toolVersionsPath = ".tool-versions"
+	}
+	contents, err := c.ReadFile(toolVersionsPath)
+	if errors.Is(err, codebase.NotFoundError) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if label.Dependencies == nil {
+		label.Dependencies = make(map[string]string)
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "elixir":
+			// like 1.15.7-otp-26, for Elixir 1.15.7 compiled with OTP 26
+			version, otp, _ := strings.Cut(fields[1], "-otp-")
+			label.Version = version
+			if otp != "" && label.Dependencies["erlang"] == "" {
+				label.Dependencies["erlang"] = otp
+			}
+		case "erlang":
+			label.Dependencies["erlang"] = fields[1]
+		}
+	}
+	return toolVersionsPath, nil
+}
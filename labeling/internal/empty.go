@@ -15,18 +15,23 @@ var ignoreList = []string{
 }
 
 var EmptyRepoRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.EmptyRepo
-		_, err = c.FindFileMatching(func(path string) bool {
-			path = strings.TrimSpace(strings.ToLower(path))
-			return !shouldIgnorePath(path)
-		}, "*")
+	{
+		ID:          "cicd/empty",
+		Description: "Applies when the repo has no files other than README.md",
+		Produces:    []string{labels.EmptyRepo},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.EmptyRepo
+			_, err = c.FindFileMatching(func(path string) bool {
+				path = strings.TrimSpace(strings.ToLower(path))
+				return !shouldIgnorePath(path)
+			}, "*")
 
-		if errors.Is(err, codebase.NotFoundError) {
-			label.Valid = true
-			return label, nil
-		}
-		return label, err
+			if errors.Is(err, codebase.NotFoundError) {
+				label.Valid = true
+				return label, nil
+			}
+			return label, err
+		},
 	},
 }
 
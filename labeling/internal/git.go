@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+var GitSubmoduleRules = []labels.Rule{
+	{
+		ID:          "vcs/git-submodules",
+		Description: "Reads the git submodules in .gitmodules",
+		Produces:    []string{labels.VCSGitSubmodules},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.VCSGitSubmodules
+			contents, err := c.ReadFile(".gitmodules")
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+			label.Dependencies = parseGitmodules(string(contents))
+			label.Valid = len(label.Dependencies) > 0
+			label.BasePath = "."
+			label.AddFiles(".gitmodules")
+			return label, nil
+		},
+	},
+}
+
+// parseGitmodules returns the path and URL of each submodule in a .gitmodules file,
+// which has the syntax of git config files:
+//
+//	[submodule "libs/shared"]
+//		path = libs/shared
+//		url = https://github.com/example/shared.git
+func parseGitmodules(contents string) map[string]string {
+	submodules := make(map[string]string)
+	var submodulePath, url string
+	addSubmodule := func() {
+		if submodulePath != "" {
+			submodules[submodulePath] = url
+		}
+		submodulePath, url = "", ""
+	}
+
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			addSubmodule()
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "path":
+			submodulePath = value
+		case "url":
+			url = value
+		}
+	}
+	addSubmodule()
+	return submodules
+}
@@ -8,11 +8,17 @@ import (
 )
 
 var GithubActionRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.CICDGithubActions
-		configPath, err := c.FindFile(".github/workflows/*.yml", ".github/workflows/*.yaml")
-		label.Valid = configPath != ""
-		label.BasePath = path.Dir(configPath)
-		return label, err
+	{
+		ID:          "cicd/github-actions",
+		Description: "Finds github actions workflows",
+		Produces:    []string{labels.CICDGithubActions},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.CICDGithubActions
+			configPath, err := c.FindFile(".github/workflows/*.{yml,yaml}")
+			label.Valid = configPath != ""
+			label.BasePath = path.Dir(configPath)
+			label.AddFiles(configPath)
+			return label, err
+		},
 	},
 }
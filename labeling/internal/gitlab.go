@@ -8,11 +8,17 @@ import (
 )
 
 var GitlabWorkflowRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.CICDGitlabWorkflow
-		configPath, err := c.FindFile(".gitlab-ci.yml")
-		label.Valid = configPath != ""
-		label.BasePath = path.Dir(configPath)
-		return label, err
+	{
+		ID:          "cicd/gitlab-workflows",
+		Description: "Finds .gitlab-ci.yml",
+		Produces:    []string{labels.CICDGitlabWorkflow},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.CICDGitlabWorkflow
+			configPath, err := c.FindFile(".gitlab-ci.yml")
+			label.Valid = configPath != ""
+			label.BasePath = path.Dir(configPath)
+			label.AddFiles(configPath)
+			return label, err
+		},
 	},
 }
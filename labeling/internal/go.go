@@ -10,34 +10,49 @@ import (
 )
 
 var GoRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.DepsGo
-		goModPath, err := c.FindFile("go.mod")
-		label.Valid = goModPath != ""
-		label.BasePath = path.Dir(goModPath)
-		lockFilePath, _ := c.FindFile("go.sum")
-		label.LabelData.HasLockFile = lockFilePath != ""
-		return label, err
-	},
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.ArtifactGoExecutable
-		if !ls[labels.DepsGo].Valid {
+	{
+		ID:          "go/deps",
+		Description: "Finds go.mod and go.sum",
+		Produces:    []string{labels.DepsGo},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsGo
+			goModPath, err := c.FindFile("go.mod")
+			label.Valid = goModPath != ""
+			label.BasePath = path.Dir(goModPath)
+			lockFilePath, _ := c.FindFile("go.sum")
+			label.LabelData.HasLockFile = lockFilePath != ""
+			label.AddFiles(goModPath, lockFilePath)
 			return label, err
-		}
+		},
+	},
+	{
+		ID:          "go/executable",
+		Description: "Finds go files in package main",
+		Produces:    []string{labels.ArtifactGoExecutable},
+		DependsOn:   []string{labels.DepsGo},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.ArtifactGoExecutable
+			if !ls[labels.DepsGo].Valid {
+				return label, err
+			}
 
-		label.Valid = containsMainGoFiles(c)
-		return label, err
+			mainFile := findMainGoFile(c)
+			label.Valid = mainFile != ""
+			label.AddFiles(mainFile)
+			return label, err
+		},
 	},
 }
 
-func containsMainGoFiles(c codebase.Codebase) bool {
-	_, err := c.FindFileMatching(
+// findMainGoFile returns the path of the first go file in package main, or "" if there are none
+func findMainGoFile(c codebase.Codebase) string {
+	mainFile, _ := c.FindFileMatching(
 		func(path string) bool {
 			return isMainPackageGoFile(c, path)
 		},
 		"*.go",
 	)
-	return err == nil
+	return mainFile
 }
 
 func isMainPackageGoFile(c codebase.Codebase, path string) bool {
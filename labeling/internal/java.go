@@ -8,17 +8,30 @@ import (
 )
 
 var JavaRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.DepsJava
-		pomXml, err := c.FindFile("pom.xml", "gradlew")
-		label.Valid = pomXml != ""
-		label.BasePath = path.Dir(pomXml)
-		return label, err
+	{
+		ID:          "java/deps",
+		Description: "Finds pom.xml or gradlew",
+		Produces:    []string{labels.DepsJava},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsJava
+			pomXml, err := c.FindFile("pom.xml", "gradlew")
+			label.Valid = pomXml != ""
+			label.BasePath = path.Dir(pomXml)
+			label.AddFiles(pomXml)
+			return label, err
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.ToolGradle
-		gradlew, err := c.FindFile("gradlew")
-		label.Valid = gradlew != "" && path.Dir(gradlew) == ls[labels.DepsJava].BasePath
-		return label, err
+	{
+		ID:          "java/gradle",
+		Description: "Finds gradlew in the java project dir",
+		Produces:    []string{labels.ToolGradle},
+		DependsOn:   []string{labels.DepsJava},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.ToolGradle
+			gradlew, err := c.FindFile("gradlew")
+			label.Valid = gradlew != "" && path.Dir(gradlew) == ls[labels.DepsJava].BasePath
+			label.AddFiles(gradlew)
+			return label, err
+		},
 	},
 }
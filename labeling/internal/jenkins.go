@@ -8,11 +8,17 @@ import (
 )
 
 var JenkinsRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.CICDJenkins
-		configPath, err := c.FindFile("Jenkinsfile")
-		label.Valid = configPath != ""
-		label.BasePath = path.Dir(configPath)
-		return label, err
+	{
+		ID:          "cicd/jenkins",
+		Description: "Finds Jenkinsfile",
+		Produces:    []string{labels.CICDJenkins},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.CICDJenkins
+			configPath, err := c.FindFile("Jenkinsfile")
+			label.Valid = configPath != ""
+			label.BasePath = path.Dir(configPath)
+			label.AddFiles(configPath)
+			return label, err
+		},
 	},
 }
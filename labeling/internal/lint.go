@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"path"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// lintSetting is a section of a shared config file configuring a linter, like the
+// [tool.ruff] section of pyproject.toml
+type lintSetting struct {
+	file     string
+	contains string
+}
+
+// lintRule returns the Run func of a rule labeling key when, in the dir of the project
+// labeled depsKey, there's one of configFiles, or one of settings
+func lintRule(key string, depsKey string, configFiles []string, settings ...lintSetting) func(codebase.Codebase, labels.LabelSet) (labels.Label, error) {
+	return func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+		label.Key = key
+		if !ls[depsKey].Valid {
+			return label, nil
+		}
+		label.BasePath = ls[depsKey].BasePath
+
+		for _, f := range configFiles {
+			configPath := path.Join(label.BasePath, f)
+			if hasFile(c, configPath) {
+				label.Valid = true
+				label.AddFiles(configPath)
+				return label, nil
+			}
+		}
+		for _, s := range settings {
+			settingsPath := path.Join(label.BasePath, s.file)
+			if fileContainsString(c, settingsPath, s.contains) {
+				label.Valid = true
+				label.AddFiles(settingsPath)
+				return label, nil
+			}
+		}
+		return label, nil
+	}
+}
+
+var LintRules = []labels.Rule{
+	{
+		ID:          "lint/eslint",
+		Description: "Finds the ESLint config of the node project",
+		Produces:    []string{labels.LintESLint},
+		DependsOn:   []string{labels.DepsNode},
+		Run: lintRule(labels.LintESLint, labels.DepsNode,
+			[]string{
+				"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts",
+				".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml",
+			},
+			lintSetting{"package.json", `"eslintConfig"`}),
+	},
+	{
+		ID:          "lint/prettier",
+		Description: "Finds the Prettier config of the node project",
+		Produces:    []string{labels.LintPrettier},
+		DependsOn:   []string{labels.DepsNode},
+		Run: lintRule(labels.LintPrettier, labels.DepsNode,
+			[]string{
+				".prettierrc", ".prettierrc.json", ".prettierrc.yml", ".prettierrc.yaml", ".prettierrc.json5",
+				".prettierrc.js", ".prettierrc.cjs", ".prettierrc.mjs", ".prettierrc.toml",
+				"prettier.config.js", "prettier.config.cjs", "prettier.config.mjs",
+			}),
+	},
+	{
+		ID:          "lint/golangci-lint",
+		Description: "Finds .golangci.yml in the go project",
+		Produces:    []string{labels.LintGolangci},
+		DependsOn:   []string{labels.DepsGo},
+		Run: lintRule(labels.LintGolangci, labels.DepsGo,
+			[]string{".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"}),
+	},
+	{
+		ID:          "lint/ruff",
+		Description: "Finds ruff.toml, or ruff settings in pyproject.toml",
+		Produces:    []string{labels.LintRuff},
+		DependsOn:   []string{labels.DepsPython},
+		Run: lintRule(labels.LintRuff, labels.DepsPython,
+			[]string{"ruff.toml", ".ruff.toml"},
+			lintSetting{"pyproject.toml", "[tool.ruff"}),
+	},
+	{
+		ID:          "lint/flake8",
+		Description: "Finds .flake8, or flake8 settings in setup.cfg or tox.ini",
+		Produces:    []string{labels.LintFlake8},
+		DependsOn:   []string{labels.DepsPython},
+		Run: lintRule(labels.LintFlake8, labels.DepsPython,
+			[]string{".flake8"},
+			lintSetting{"setup.cfg", "[flake8]"},
+			lintSetting{"tox.ini", "[flake8]"}),
+	},
+	{
+		ID:          "lint/black",
+		Description: "Finds black settings in pyproject.toml",
+		Produces:    []string{labels.LintBlack},
+		DependsOn:   []string{labels.DepsPython},
+		Run: lintRule(labels.LintBlack, labels.DepsPython, nil,
+			lintSetting{"pyproject.toml", "[tool.black]"}),
+	},
+	{
+		ID:          "lint/rubocop",
+		Description: "Finds .rubocop.yml in the ruby project",
+		Produces:    []string{labels.LintRubocop},
+		DependsOn:   []string{labels.DepsRuby},
+		Run:         lintRule(labels.LintRubocop, labels.DepsRuby, []string{".rubocop.yml"}),
+	},
+	{
+		ID:          "lint/phpcs",
+		Description: "Finds the PHP_CodeSniffer config of the php project",
+		Produces:    []string{labels.LintPhpcs},
+		DependsOn:   []string{labels.DepsPhp},
+		Run: lintRule(labels.LintPhpcs, labels.DepsPhp,
+			[]string{"phpcs.xml", "phpcs.xml.dist", ".phpcs.xml", ".phpcs.xml.dist"}),
+	},
+	{
+		ID:          "lint/phpstan",
+		Description: "Finds the PHPStan config of the php project",
+		Produces:    []string{labels.LintPhpstan},
+		DependsOn:   []string{labels.DepsPhp},
+		Run: lintRule(labels.LintPhpstan, labels.DepsPhp,
+			[]string{"phpstan.neon", "phpstan.neon.dist", "phpstan.dist.neon"}),
+	},
+	{
+		ID:          "lint/clippy",
+		Description: "Finds clippy.toml, or clippy lints in Cargo.toml",
+		Produces:    []string{labels.LintClippy},
+		DependsOn:   []string{labels.DepsRust},
+		Run: lintRule(labels.LintClippy, labels.DepsRust,
+			[]string{"clippy.toml", ".clippy.toml"},
+			lintSetting{"Cargo.toml", "[lints.clippy]"},
+			lintSetting{"Cargo.toml", "[workspace.lints.clippy]"}),
+	},
+	{
+		ID:          "lint/rustfmt",
+		Description: "Finds rustfmt.toml in the rust project",
+		Produces:    []string{labels.LintRustfmt},
+		DependsOn:   []string{labels.DepsRust},
+		Run:         lintRule(labels.LintRustfmt, labels.DepsRust, []string{"rustfmt.toml", ".rustfmt.toml"}),
+	},
+	{
+		ID:          "lint/checkstyle",
+		Description: "Finds the Checkstyle plugin in the java build",
+		Produces:    []string{labels.LintCheckstyle},
+		DependsOn:   []string{labels.DepsJava},
+		Run: lintRule(labels.LintCheckstyle, labels.DepsJava, nil,
+			lintSetting{"build.gradle", "checkstyle"},
+			lintSetting{"build.gradle.kts", "checkstyle"},
+			lintSetting{"pom.xml", "maven-checkstyle-plugin"}),
+	},
+	{
+		ID:          "lint/spotless",
+		Description: "Finds the Spotless plugin in the java build",
+		Produces:    []string{labels.LintSpotless},
+		DependsOn:   []string{labels.DepsJava},
+		Run: lintRule(labels.LintSpotless, labels.DepsJava, nil,
+			lintSetting{"build.gradle", "com.diffplug.spotless"},
+			lintSetting{"build.gradle.kts", "com.diffplug.spotless"},
+			lintSetting{"pom.xml", "spotless-maven-plugin"}),
+	},
+}
@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"bytes"
 	"encoding/json"
 	"path"
+	"strings"
 
 	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
@@ -11,47 +13,250 @@ import (
 var lockFiles = []string{
 	"package-lock.json",
 	"yarn.lock",
+	"bun.lockb",
+	"bun.lock",
+	"pnpm-lock.yaml",
 }
 
 var NodeRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.DepsNode
-		packagePath := findPackageJSON(c)
-		label.Valid = packagePath != ""
-		if !label.Valid {
-			return label, err
-		}
-		err = readPackageJSON(c, packagePath, &label)
+	{
+		ID:          "node/deps",
+		Description: "Finds package.json and reads its dependencies and scripts",
+		Produces:    []string{labels.DepsNode},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsNode
+			packagePath := findPackageJSON(c)
+			label.Valid = packagePath != ""
+			if !label.Valid {
+				return label, err
+			}
+			err = readPackageJSON(c, packagePath, &label)
 
-		// Lock files
-		lockFilesPath, _ := c.FindFile(lockFiles...)
-		label.LabelData.HasLockFile = lockFilesPath != ""
+			// Lock files
+			lockFilesPath, _ := c.FindFile(lockFiles...)
+			label.LabelData.HasLockFile = lockFilesPath != ""
+			label.AddFiles(packagePath, lockFilesPath)
 
-		return label, err
+			return label, err
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.PackageManagerYarn
-		yarnLock, _ := c.FindFile("yarn.lock")
+	{
+		ID:          "node/yarn",
+		Description: "Finds yarn.lock and .yarnrc.yml to tell yarn classic from berry",
+		Produces:    []string{labels.PackageManagerYarn},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.PackageManagerYarn
+			yarnLock, _ := c.FindFile("yarn.lock")
+
+			if yarnLock == "" {
+				return label, err
+			}
+
+			label.Valid = true
+			label.Version = "classic"
+
+			yarnrc, _ := c.FindFile(".yarnrc.yml", ".yarnrc.yaml")
+			if yarnrc != "" {
+				label.Version = "berry"
+			}
+			label.AddFiles(yarnLock, yarnrc)
 
-		if yarnLock == "" {
 			return label, err
-		}
+		},
+	},
+	{
+		ID:          "node/bun",
+		Description: "Finds bun.lockb, bun.lock or bunfig.toml next to the root package.json",
+		Produces:    []string{labels.PackageManagerBun},
+		DependsOn:   []string{labels.DepsNode},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.PackageManagerBun
+			bunFile := findNodeFile(c, ls, "bun.lockb", "bun.lock", "bunfig.toml")
+			label.Valid = bunFile != ""
+			label.BasePath = path.Dir(bunFile)
+			label.AddFiles(bunFile)
+			return label, err
+		},
+	},
+	{
+		ID: "node/pnpm",
+		Description: "Finds pnpm-lock.yaml or pnpm-workspace.yaml next to the root package.json, " +
+			"and the pnpm version in package.json",
+		Produces:  []string{labels.PackageManagerPnpm},
+		DependsOn: []string{labels.DepsNode},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.PackageManagerPnpm
+			pnpmFile := findNodeFile(c, ls, "pnpm-lock.yaml", "pnpm-workspace.yaml")
+			label.Valid = pnpmFile != ""
+			if !label.Valid {
+				return label, nil
+			}
+			label.BasePath = path.Dir(pnpmFile)
+			label.AddFiles(pnpmFile)
 
-		label.Valid = true
-		label.Version = "classic"
+			packagePath := path.Join(ls[labels.DepsNode].BasePath, "package.json")
+			packageJSON, err := codebase.ReadJSON[npmPackageJSON](c, packagePath)
+			if err != nil {
+				return label, err
+			}
+			// like "pnpm@8.15.0+sha256.abc..."
+			if version, ok := strings.CutPrefix(packageJSON.PackageManager, "pnpm@"); ok {
+				label.Version, _, _ = strings.Cut(version, "+")
+				label.AddFiles(packagePath)
+			}
+			return label, nil
+		},
+	},
+	{
+		ID:          "node/workspaces",
+		Description: "Finds workspaces in package.json, or pnpm-workspace.yaml",
+		Produces:    []string{labels.WorkspacesNode},
+		DependsOn:   []string{labels.DepsNode},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.WorkspacesNode
+			if !ls[labels.DepsNode].Valid {
+				return label, nil
+			}
+			label.BasePath = ls[labels.DepsNode].BasePath
 
-		yarnrc, _ := c.FindFile(".yarnrc.yml", ".yarnrc.yaml")
-		if yarnrc != "" {
-			label.Version = "berry"
-		}
+			pnpmWorkspace := path.Join(label.BasePath, "pnpm-workspace.yaml")
+			if hasFile(c, pnpmWorkspace) {
+				label.Valid = true
+				label.AddFiles(pnpmWorkspace)
+				return label, nil
+			}
 
-		return label, err
+			packagePath := path.Join(label.BasePath, "package.json")
+			packageJSON, err := codebase.ReadJSON[npmPackageJSON](c, packagePath)
+			if err != nil {
+				return label, err
+			}
+			// either a list of globs, or an object with a packages list of globs
+			workspaces := bytes.TrimSpace(packageJSON.Workspaces)
+			label.Valid = len(workspaces) > 0 && !bytes.Equal(workspaces, []byte("null")) &&
+				!bytes.Equal(workspaces, []byte("[]"))
+			label.AddFiles(packagePath)
+			return label, nil
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.TestJest
-		label.Valid = hasDependency(ls, "jest")
-		return label, err
+	{
+		ID:          "node/turborepo",
+		Description: "Finds turbo.json next to the root package.json",
+		Produces:    []string{labels.ToolTurborepo},
+		DependsOn:   []string{labels.DepsNode},
+		Run:         nodeToolRule(labels.ToolTurborepo, "turbo.json"),
+	},
+	{
+		ID:          "node/nx",
+		Description: "Finds nx.json next to the root package.json",
+		Produces:    []string{labels.ToolNx},
+		DependsOn:   []string{labels.DepsNode},
+		Run:         nodeToolRule(labels.ToolNx, "nx.json"),
+	},
+	{
+		ID:          "node/lerna",
+		Description: "Finds lerna.json next to the root package.json",
+		Produces:    []string{labels.ToolLerna},
+		DependsOn:   []string{labels.DepsNode},
+		Run:         nodeToolRule(labels.ToolLerna, "lerna.json"),
+	},
+	{
+		ID:          "node/jest",
+		Description: "Finds jest in the node dependencies",
+		Produces:    []string{labels.TestJest},
+		DependsOn:   []string{labels.DepsNode},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.TestJest
+			label.Valid = hasDependency(ls, "jest")
+			if label.Valid {
+				label.AddFiles(path.Join(ls[labels.DepsNode].BasePath, "package.json"))
+				label.Evidence.Dependencies = []string{"jest"}
+			}
+			return label, err
+		},
+	},
+	{
+		ID:          "node/vitest",
+		Description: "Finds vitest in the node dependencies",
+		Produces:    []string{labels.TestVitest},
+		DependsOn:   []string{labels.DepsNode},
+		Run:         nodeDependencyRule(labels.TestVitest, "vitest"),
+	},
+	{
+		ID:          "node/mocha",
+		Description: "Finds mocha in the node dependencies",
+		Produces:    []string{labels.TestMocha},
+		DependsOn:   []string{labels.DepsNode},
+		Run:         nodeDependencyRule(labels.TestMocha, "mocha"),
+	},
+	{
+		ID:          "node/ava",
+		Description: "Finds ava in the node dependencies",
+		Produces:    []string{labels.TestAva},
+		DependsOn:   []string{labels.DepsNode},
+		Run:         nodeDependencyRule(labels.TestAva, "ava"),
+	},
+	{
+		ID:          "node/playwright",
+		Description: "Finds @playwright/test in the node dependencies",
+		Produces:    []string{labels.TestPlaywright},
+		DependsOn:   []string{labels.DepsNode},
+		Run:         nodeDependencyRule(labels.TestPlaywright, "@playwright/test"),
 	},
+	{
+		ID:          "node/cypress",
+		Description: "Finds cypress in the node dependencies",
+		Produces:    []string{labels.TestCypress},
+		DependsOn:   []string{labels.DepsNode},
+		Run:         nodeDependencyRule(labels.TestCypress, "cypress"),
+	},
+}
+
+// nodeDependencyRule returns the Run func of a rule labeling key when dep is one of the
+// node dependencies, with the version range of dep as its version
+func nodeDependencyRule(key string, dep string) func(codebase.Codebase, labels.LabelSet) (labels.Label, error) {
+	return func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+		label.Key = key
+		label.Valid = hasDependency(ls, dep)
+		if label.Valid {
+			label.BasePath = ls[labels.DepsNode].BasePath
+			label.Version = ls[labels.DepsNode].Dependencies[dep]
+			label.AddFiles(path.Join(ls[labels.DepsNode].BasePath, "package.json"))
+			label.Evidence.Dependencies = []string{dep}
+		}
+		return label, err
+	}
+}
+
+// findNodeFile returns the path of the first of files that is next to the root
+// package.json, or "" if there is none
+func findNodeFile(c codebase.Codebase, ls labels.LabelSet, files ...string) string {
+	if !ls[labels.DepsNode].Valid {
+		return ""
+	}
+	for _, file := range files {
+		filePath := path.Join(ls[labels.DepsNode].BasePath, file)
+		if hasFile(c, filePath) {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// nodeToolRule returns the Run func of a rule labeling key when configFile is next to
+// the root package.json, like the turbo.json of a Turborepo
+func nodeToolRule(key string, configFile string) func(codebase.Codebase, labels.LabelSet) (labels.Label, error) {
+	return func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+		label.Key = key
+		if !ls[labels.DepsNode].Valid {
+			return label, nil
+		}
+		label.BasePath = ls[labels.DepsNode].BasePath
+		configPath := path.Join(label.BasePath, configFile)
+		label.Valid = hasFile(c, configPath)
+		label.AddFiles(configPath)
+		return label, nil
+	}
 }
 
 // npmPackageJSON for unmarshalling npm package.json files
@@ -59,6 +264,8 @@ type npmPackageJSON struct {
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
 	Scripts         map[string]string `json:"scripts"`
+	Workspaces      json.RawMessage   `json:"workspaces"`
+	PackageManager  string            `json:"packageManager"`
 }
 
 func findPackageJSON(c codebase.Codebase) string {
@@ -67,21 +274,20 @@ func findPackageJSON(c codebase.Codebase) string {
 }
 
 func readPackageJSON(c codebase.Codebase, filePath string, label *labels.Label) error {
-	var packageJSON npmPackageJSON
-
-	fileContents, err := c.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-
-	err = json.Unmarshal(fileContents, &packageJSON)
+	packageJSON, err := codebase.ReadJSON[npmPackageJSON](c, filePath)
 	if err != nil {
 		return err
 	}
 
 	label.LabelData.BasePath = path.Dir(filePath)
-	label.Tasks = packageJSON.Scripts
+	// packageJSON may be shared with other rules, so its maps are copied
 	label.Dependencies = make(map[string]string)
+	if packageJSON.Scripts != nil {
+		label.Tasks = make(map[string]string)
+		for k, v := range packageJSON.Scripts {
+			label.Tasks[k] = v
+		}
+	}
 
 	for k, v := range packageJSON.Dependencies {
 		label.Dependencies[k] = v
@@ -1,7 +1,6 @@
 package internal
 
 import (
-	"encoding/json"
 	"errors"
 	"path"
 
@@ -10,22 +9,28 @@ import (
 )
 
 var PhpRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.DepsPhp
-		label.Dependencies = make(map[string]string)
-
-		composerPath, err := c.FindFile("composer.json")
-		if err != nil && !errors.Is(err, codebase.NotFoundError) {
+	{
+		ID:          "php/deps",
+		Description: "Finds composer.json and reads its dependencies",
+		Produces:    []string{labels.DepsPhp},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsPhp
+			label.Dependencies = make(map[string]string)
+
+			composerPath, err := c.FindFile("composer.json")
+			if err != nil && !errors.Is(err, codebase.NotFoundError) {
+				return label, err
+
+			}
+			if composerPath != "" {
+				label.Valid = true
+				label.BasePath = path.Dir(composerPath)
+				label.AddFiles(composerPath)
+			}
+
+			err = readComposerFile(c, composerPath, &label)
 			return label, err
-
-		}
-		if composerPath != "" {
-			label.Valid = true
-			label.BasePath = path.Dir(composerPath)
-		}
-
-		err = readComposerFile(c, composerPath, &label)
-		return label, err
+		},
 	},
 }
 
@@ -35,12 +40,7 @@ type composerJSON struct {
 }
 
 func readComposerFile(c codebase.Codebase, filePath string, label *labels.Label) error {
-	fileContents, err := c.ReadFile(filePath)
-	if err != nil {
-		return err
-	}
-	var composerDeps composerJSON
-	err = json.Unmarshal(fileContents, &composerDeps)
+	composerDeps, err := codebase.ReadJSON[composerJSON](c, filePath)
 	if err != nil {
 		return err
 	}
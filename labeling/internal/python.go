@@ -8,7 +8,6 @@ import (
 
 	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
-	"github.com/pelletier/go-toml"
 )
 
 var pipenvFiles = []string{
@@ -35,79 +34,117 @@ var possiblePythonFiles = append(
 )
 
 var PythonRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
-		label := labels.Label{
-			Key: labels.DepsPython,
-		}
-		filePath, _ := c.FindFile(possiblePythonFiles...)
-		label.Valid = filePath != ""
-		label.BasePath = path.Dir(filePath)
-
-		pythonVersion := getPythonVersion(c)
-		if pythonVersion != "" {
-			label.Dependencies = map[string]string{
-				"python": pythonVersion,
+	{
+		ID:          "python/deps",
+		Description: "Finds python dependency files and the python version",
+		Produces:    []string{labels.DepsPython},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+			label := labels.Label{
+				Key: labels.DepsPython,
+			}
+			filePath, _ := c.FindFile(possiblePythonFiles...)
+			label.Valid = filePath != ""
+			label.BasePath = path.Dir(filePath)
+			label.AddFiles(filePath)
+
+			pythonVersion := getPythonVersion(c)
+			if pythonVersion != "" {
+				label.Dependencies = map[string]string{
+					"python": pythonVersion,
+				}
 			}
-		}
 
-		return label, nil
+			return label, nil
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
-		label := labels.Label{
-			Key: labels.PackageManagerPipenv,
-		}
-		pipfile, _ := c.FindFile(pipenvFiles...)
-		label.Valid = pipfile != ""
-		label.BasePath = path.Dir(pipfile)
-
-		pyprojectPath, _ := c.FindFile("pyproject.toml")
-		if pyprojectPath != "" && fileContainsString(c, pyprojectPath, "pipenv") {
-			label.Valid = true
-			label.BasePath = path.Dir(pyprojectPath)
-		}
+	{
+		ID:          "python/pipenv",
+		Description: "Finds Pipfile, Pipfile.lock or pipenv in pyproject.toml",
+		Produces:    []string{labels.PackageManagerPipenv},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+			label := labels.Label{
+				Key: labels.PackageManagerPipenv,
+			}
+			pipfile, _ := c.FindFile(pipenvFiles...)
+			label.Valid = pipfile != ""
+			label.BasePath = path.Dir(pipfile)
+			label.AddFiles(pipfile)
+
+			pyprojectPath, _ := c.FindFile("pyproject.toml")
+			if pyprojectPath != "" && fileContainsString(c, pyprojectPath, "pipenv") {
+				label.Valid = true
+				label.BasePath = path.Dir(pyprojectPath)
+				label.AddFiles(pyprojectPath)
+			}
 
-		return label, nil
+			return label, nil
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
-		label := labels.Label{
-			Key: labels.PackageManagerPoetry,
-		}
-		poetryLock, _ := c.FindFile(poetryFiles...)
-		label.Valid = poetryLock != ""
-		label.BasePath = path.Dir(poetryLock)
-
-		pyprojectPath, _ := c.FindFile("pyproject.toml")
-		if pyprojectPath != "" && fileContainsString(c, pyprojectPath, "poetry") {
-			label.Valid = true
-			label.BasePath = path.Dir(pyprojectPath)
-		}
+	{
+		ID:          "python/poetry",
+		Description: "Finds poetry.lock or poetry in pyproject.toml",
+		Produces:    []string{labels.PackageManagerPoetry},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+			label := labels.Label{
+				Key: labels.PackageManagerPoetry,
+			}
+			poetryLock, _ := c.FindFile(poetryFiles...)
+			label.Valid = poetryLock != ""
+			label.BasePath = path.Dir(poetryLock)
+			label.AddFiles(poetryLock)
+
+			pyprojectPath, _ := c.FindFile("pyproject.toml")
+			if pyprojectPath != "" && fileContainsString(c, pyprojectPath, "poetry") {
+				label.Valid = true
+				label.BasePath = path.Dir(pyprojectPath)
+				label.AddFiles(pyprojectPath)
+			}
 
-		return label, nil
+			return label, nil
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
-		label := labels.Label{
-			Key: labels.FileManagePy,
-		}
-		managePyPath, _ := c.FindFile("manage.py")
-		label.Valid = managePyPath != ""
-		label.BasePath = path.Dir(managePyPath)
-		return label, nil
+	{
+		ID:          "python/manage-py",
+		Description: "Finds manage.py",
+		Produces:    []string{labels.FileManagePy},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+			label := labels.Label{
+				Key: labels.FileManagePy,
+			}
+			managePyPath, _ := c.FindFile("manage.py")
+			label.Valid = managePyPath != ""
+			label.BasePath = path.Dir(managePyPath)
+			label.AddFiles(managePyPath)
+			return label, nil
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
-		label := labels.Label{
-			Key: labels.FileSetupPy,
-		}
-		setupPath, _ := c.FindFile("setup.py")
-		label.Valid = setupPath != ""
-		label.BasePath = path.Dir(setupPath)
-		return label, nil
+	{
+		ID:          "python/setup-py",
+		Description: "Finds setup.py",
+		Produces:    []string{labels.FileSetupPy},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+			label := labels.Label{
+				Key: labels.FileSetupPy,
+			}
+			setupPath, _ := c.FindFile("setup.py")
+			label.Valid = setupPath != ""
+			label.BasePath = path.Dir(setupPath)
+			label.AddFiles(setupPath)
+			return label, nil
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
-		label := labels.Label{Key: labels.TestTox}
-		toxPath, _ := c.FindFile("tox.ini")
-		label.Valid = toxPath != ""
-		label.BasePath = path.Dir(toxPath)
-		return label, nil
+	{
+		ID:          "python/tox",
+		Description: "Finds tox.ini",
+		Produces:    []string{labels.TestTox},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+			label := labels.Label{Key: labels.TestTox}
+			toxPath, _ := c.FindFile("tox.ini")
+			label.Valid = toxPath != ""
+			label.BasePath = path.Dir(toxPath)
+			label.AddFiles(toxPath)
+			return label, nil
+		},
 	},
 }
 
@@ -125,6 +162,22 @@ func fileContainsString(c codebase.Codebase, filePath string, str string) bool {
 	return strings.Contains(fileStr, str)
 }
 
+// pyprojectTOML is the part of pyproject.toml files the python rules read
+type pyprojectTOML struct {
+	Tool struct {
+		Poetry struct {
+			Dependencies map[string]interface{} `toml:"dependencies"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// pipfileTOML is the part of Pipfile files the python rules read
+type pipfileTOML struct {
+	Requires struct {
+		PythonVersion string `toml:"python_version"`
+	} `toml:"requires"`
+}
+
 func getPythonVersion(c codebase.Codebase) string {
 
 	versionFilePath, _ := c.FindFile(".python-version")
@@ -146,45 +199,35 @@ func getPythonVersion(c codebase.Codebase) string {
 
 	pyprojectFilePath, _ := c.FindFile("pyproject.toml")
 	if pyprojectFilePath != "" {
-		file, err := c.ReadFile(pyprojectFilePath)
+		pyproject, err := codebase.ReadTOML[pyprojectTOML](c, pyprojectFilePath)
 		if err != nil {
 			log.Println("Unable to read file pyproject.toml. Attempting to determine python version another way... ", err)
 		} else {
-			tree, err := toml.LoadBytes(file)
-			if err != nil {
-				log.Println("Unable to read file pyproject.toml. Attempting to determine python version another way... ", err)
+			pythonVersion, _ := pyproject.Tool.Poetry.Dependencies["python"].(string)
+			if pythonVersion == "" {
+				log.Println("Error parsing pyproject.toml, python version is nil")
 			} else {
-				pythonVersion := tree.Get("tool.poetry.dependencies.python")
-				if pythonVersion == nil || pythonVersion.(string) == "" {
-					log.Println("Error parsing pyproject.toml, python version is nil")
-				} else {
-					pythonVersion = strings.TrimPrefix(pythonVersion.(string), "^")
-					log.Println("Found Python version in pyproject.toml file: ", pythonVersion.(string))
-					return pythonVersion.(string)
-				}
+				pythonVersion = strings.TrimPrefix(pythonVersion, "^")
+				log.Println("Found Python version in pyproject.toml file: ", pythonVersion)
+				return pythonVersion
 			}
 		}
 	}
 
 	pipfileFilePath, _ := c.FindFile("Pipfile")
 	if pipfileFilePath != "" {
-		file, err := c.ReadFile(pipfileFilePath)
+		pipfile, err := codebase.ReadTOML[pipfileTOML](c, pipfileFilePath)
 		if err != nil {
 			log.Println("Unable to read file Pipfile. Attempting to determine python version another way... ", err)
 		} else {
-			tree, err := toml.LoadBytes(file)
-			if err != nil {
-				log.Println("Unable to read file Pipfile. Attempting to determine python version another way... ", err)
+			pythonVersion := pipfile.Requires.PythonVersion
+			if pythonVersion == "" {
+				log.Println("Error parsing Pipfile, returning nil")
+				return ""
 			} else {
-				pythonVersion := tree.Get("requires.python_version")
-				if pythonVersion == nil || pythonVersion.(string) == "" {
-					log.Println("Error parsing Pipfile, returning nil")
-					return ""
-				} else {
-					pythonVersion = strings.TrimPrefix(pythonVersion.(string), "^")
-					log.Println("Found Python version in Pipfile: ", pythonVersion.(string))
-					return pythonVersion.(string)
-				}
+				pythonVersion = strings.TrimPrefix(pythonVersion, "^")
+				log.Println("Found Python version in Pipfile: ", pythonVersion)
+				return pythonVersion
 			}
 		}
 	}
@@ -10,43 +10,59 @@ import (
 )
 
 var RubyRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.DepsRuby
-		label.Dependencies = make(map[string]string)
+	{
+		ID:          "ruby/deps",
+		Description: "Finds Gemfile, Gemfile.lock and reads the gems used",
+		Produces:    []string{labels.DepsRuby},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsRuby
+			label.Dependencies = make(map[string]string)
 
-		gemfilePath, err := c.FindFile("Gemfile")
-		if err != nil && !errors.Is(err, codebase.NotFoundError) {
-			return label, err
-		}
-		if gemfilePath != "" {
-			label.Valid = true
-			label.BasePath = path.Dir(gemfilePath)
-			err = readDepsFile(c, label.Dependencies, gemfilePath)
-			if err != nil {
+			gemfilePath, err := c.FindFile("Gemfile")
+			if err != nil && !errors.Is(err, codebase.NotFoundError) {
 				return label, err
 			}
-			label.HasLockFile = hasPath(c, path.Join(path.Dir(gemfilePath), "Gemfile.lock"))
-		}
-		return label, nil
+			if gemfilePath != "" {
+				label.Valid = true
+				label.BasePath = path.Dir(gemfilePath)
+				err = readDepsFile(c, label.Dependencies, gemfilePath)
+				if err != nil {
+					return label, err
+				}
+				lockFilePath := path.Join(path.Dir(gemfilePath), "Gemfile.lock")
+				label.HasLockFile = hasPath(c, lockFilePath)
+				label.AddFiles(gemfilePath)
+				if label.HasLockFile {
+					label.AddFiles(lockFilePath)
+				}
+			}
+			return label, nil
+		},
 	},
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.PackageManagerGemspec
-		label.Dependencies = make(map[string]string)
-
-		gemspecPath, err := c.FindFile("*.gemspec")
-		if err != nil && !errors.Is(err, codebase.NotFoundError) {
-			return label, err
-		}
+	{
+		ID:          "ruby/gemspec",
+		Description: "Finds a .gemspec file and reads the gems used",
+		Produces:    []string{labels.PackageManagerGemspec},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.PackageManagerGemspec
+			label.Dependencies = make(map[string]string)
 
-		if gemspecPath != "" {
-			label.Valid = true
-			label.BasePath = path.Dir(gemspecPath)
-			err = readDepsFile(c, label.Dependencies, gemspecPath)
-			if err != nil {
+			gemspecPath, err := c.FindFile("*.gemspec")
+			if err != nil && !errors.Is(err, codebase.NotFoundError) {
 				return label, err
 			}
-		}
-		return label, nil
+
+			if gemspecPath != "" {
+				label.Valid = true
+				label.BasePath = path.Dir(gemspecPath)
+				label.AddFiles(gemspecPath)
+				err = readDepsFile(c, label.Dependencies, gemspecPath)
+				if err != nil {
+					return label, err
+				}
+			}
+			return label, nil
+		},
 	},
 }
 
@@ -8,11 +8,17 @@ import (
 )
 
 var RustRules = []labels.Rule{
-	func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
-		label.Key = labels.DepsRust
-		cargoTomlFile, err := c.FindFile("Cargo.toml", "cargo.toml")
-		label.Valid = cargoTomlFile != ""
-		label.BasePath = path.Dir(cargoTomlFile)
-		return label, err
+	{
+		ID:          "rust/deps",
+		Description: "Finds Cargo.toml",
+		Produces:    []string{labels.DepsRust},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsRust
+			cargoTomlFile, err := c.FindFile("Cargo.toml", "cargo.toml")
+			label.Valid = cargoTomlFile != ""
+			label.BasePath = path.Dir(cargoTomlFile)
+			label.AddFiles(cargoTomlFile)
+			return label, err
+		},
 	},
 }
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+// scalaTestFrameworks are the artifacts of the test frameworks sbt runs tests with
+var scalaTestFrameworks = []string{"scalatest", "munit", "specs2-core", "utest", "zio-test", "weaver-cats", "scalacheck"}
+
+var scalaVersionPattern = regexp.MustCompile(`scalaVersion\s*:=\s*"([^"]+)"`)
+
+// sbtDependencyPattern matches dependencies like "org.scalatest" %% "scalatest" % "3.2.17"
+var sbtDependencyPattern = regexp.MustCompile(`"[^"]+"\s*%%?%?\s*"([^"]+)"\s*%\s*"([^"]+)"`)
+
+var ScalaRules = []labels.Rule{
+	{
+		ID:          "scala/deps",
+		Description: "Finds build.sbt and reads the Scala version and the dependencies",
+		Produces:    []string{labels.DepsScala},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsScala
+			buildSbt, err := c.FindFile("build.sbt")
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+			contents, err := c.ReadFile(buildSbt)
+			if err != nil {
+				return label, err
+			}
+
+			label.Valid = true
+			label.BasePath = path.Dir(buildSbt)
+			if m := scalaVersionPattern.FindSubmatch(contents); m != nil {
+				label.Version = string(m[1])
+			}
+			label.Dependencies = make(map[string]string)
+			for _, m := range sbtDependencyPattern.FindAllSubmatch(contents, -1) {
+				label.Dependencies[string(m[1])] = string(m[2])
+			}
+			for _, framework := range scalaTestFrameworks {
+				if label.Dependencies[framework] != "" {
+					label.Evidence.Dependencies = append(label.Evidence.Dependencies, framework)
+				}
+			}
+			label.AddFiles(buildSbt)
+			return label, nil
+		},
+	},
+	{
+		ID:          "scala/sbt-assembly",
+		Description: "Finds the sbt-assembly plugin in project/plugins.sbt",
+		Produces:    []string{labels.ArtifactSbtAssembly},
+		DependsOn:   []string{labels.DepsScala},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.ArtifactSbtAssembly
+			if !ls[labels.DepsScala].Valid {
+				return label, nil
+			}
+			pluginsPath := path.Join(ls[labels.DepsScala].BasePath, "project", "plugins.sbt")
+			contents, err := c.ReadFile(pluginsPath)
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+			label.Valid = strings.Contains(string(contents), `"sbt-assembly"`)
+			label.BasePath = ls[labels.DepsScala].BasePath
+			label.AddFiles(pluginsPath)
+			return label, nil
+		},
+	},
+}
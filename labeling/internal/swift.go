@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+)
+
+var swiftToolsVersionPattern = regexp.MustCompile(`swift-tools-version:\s*(\d+(\.\d+)*)`)
+
+var SwiftRules = []labels.Rule{
+	{
+		ID:          "swift/deps",
+		Description: "Finds Package.swift and its swift-tools-version",
+		Produces:    []string{labels.DepsSwift},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.DepsSwift
+			packagePath, err := c.FindFile("Package.swift")
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+			contents, err := c.ReadFile(packagePath)
+			if err != nil {
+				return label, err
+			}
+			label.Valid = true
+			label.BasePath = path.Dir(packagePath)
+			if m := swiftToolsVersionPattern.FindStringSubmatch(string(contents)); m != nil {
+				label.Version = m[1]
+			}
+			resolvedPath := path.Join(label.BasePath, "Package.resolved")
+			label.HasLockFile = hasFile(c, resolvedPath)
+			label.AddFiles(packagePath)
+			if label.HasLockFile {
+				label.AddFiles(resolvedPath)
+			}
+			return label, nil
+		},
+	},
+	{
+		// Tasks has the "workspace" or "project" to build, and the "scheme", named after it.
+		// Version is the Xcode version in .xcode-version, if there is one.
+		ID:          "xcode/project",
+		Description: "Finds an Xcode workspace, or else an Xcode project",
+		Produces:    []string{labels.ToolXcode},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.ToolXcode
+			// workspaces are dirs, so they are found by the files in them. Projects have a
+			// workspace of their own, which is not the one to build.
+			kind := "workspace"
+			found, err := c.FindFile("**/*.xcworkspace/contents.xcworkspacedata", "!**/*.xcodeproj/**", "!**/Pods/**")
+			if errors.Is(err, codebase.NotFoundError) {
+				kind = "project"
+				found, err = c.FindFile("**/*.xcodeproj/project.pbxproj", "!**/Pods/**")
+			}
+			if errors.Is(err, codebase.NotFoundError) {
+				return label, nil
+			}
+			if err != nil {
+				return label, err
+			}
+
+			bundle := path.Base(path.Dir(found))
+			label.Valid = true
+			label.BasePath = path.Dir(path.Dir(found))
+			label.Tasks = map[string]string{
+				kind:     bundle,
+				"scheme": strings.TrimSuffix(bundle, path.Ext(bundle)),
+			}
+			label.AddFiles(found)
+
+			xcodeVersionPath := path.Join(label.BasePath, ".xcode-version")
+			if contents, err := c.ReadFile(xcodeVersionPath); err == nil {
+				label.Version = strings.TrimSpace(string(contents))
+				label.AddFiles(xcodeVersionPath)
+			}
+			return label, nil
+		},
+	},
+	{
+		ID:          "cocoapods/deps",
+		Description: "Finds Podfile and Podfile.lock",
+		Produces:    []string{labels.PackageManagerCocoapods},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.PackageManagerCocoapods
+			podfile, err := c.FindFile("Podfile")
+			label.Valid = podfile != ""
+			label.BasePath = path.Dir(podfile)
+			lockPath := path.Join(label.BasePath, "Podfile.lock")
+			label.HasLockFile = label.Valid && hasFile(c, lockPath)
+			label.AddFiles(podfile)
+			if label.HasLockFile {
+				label.AddFiles(lockPath)
+			}
+			return label, err
+		},
+	},
+	{
+		ID:          "fastlane/fastfile",
+		Description: "Finds a Fastfile, in a fastlane dir or not",
+		Produces:    []string{labels.ToolFastlane},
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (label labels.Label, err error) {
+			label.Key = labels.ToolFastlane
+			fastfile, err := c.FindFile("Fastfile")
+			label.Valid = fastfile != ""
+			label.BasePath = path.Dir(fastfile)
+			if path.Base(label.BasePath) == "fastlane" {
+				label.BasePath = path.Dir(label.BasePath)
+			}
+			label.AddFiles(fastfile)
+			return label, err
+		},
+	},
+}
@@ -1,6 +1,13 @@
 package labeling
 
 import (
+	"errors"
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+
 	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
 	"github.com/CircleCI-Public/circleci-config/labeling/internal"
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
@@ -8,37 +15,229 @@ import (
 
 // ApplyRules applies the rules to a codebase.Codebase and returns a map of label key to
 // valid codebase.Label
-// Order of rules is relevant, higher "salience" rules should come first, i.e. later rules can
-// depend on the LabelData of previous rules.
-func ApplyRules(c codebase.Codebase, rules []labels.Rule) labels.LabelSet {
+// Rules are applied concurrently, on as many workers as runtime.GOMAXPROCS, see ApplyRulesN.
+func ApplyRules(c codebase.Codebase, rules []labels.Rule) (labels.LabelSet, error) {
+	return ApplyRulesN(c, rules, runtime.GOMAXPROCS(0))
+}
+
+// ApplyRulesN is like ApplyRules, but runs at most workers rules at the same time.
+// A rule runs as soon as the rules producing the keys in its DependsOn are done, and it
+// gets its own LabelSet with the labels of those rules (and of their dependencies) only.
+// The result doesn't depend on the order rules finish in: if several rules produce the
+// same key, the one that comes last in the Plan wins.
+// Rules share a codebase.CachingCodebase, so files several rules read are only read once.
+// It returns an error if rules can't be planned, or with the IDs of the rules that
+// panicked, along with the labels of the other rules.
+func ApplyRulesN(c codebase.Codebase, rules []labels.Rule, workers int) (labels.LabelSet, error) {
+	plan, err := Plan(rules)
+	if err != nil {
+		return nil, err
+	}
+	c = codebase.NewCachingCodebase(c)
+	if workers < 1 {
+		workers = 1
+	}
+
+	deps := planDependencies(plan)
+	dependents := make([][]int, len(plan))
+	pendingDeps := make([]int, len(plan))
+	for i := range plan {
+		pendingDeps[i] = len(deps[i].direct)
+		for _, d := range deps[i].direct {
+			dependents[d] = append(dependents[d], i)
+		}
+	}
+
+	// results[i] and errs[i] are only written by the worker running plan[i], and only
+	// read after that worker has reported it done
+	results := make([]*labels.Label, len(plan))
+	errs := make([]error, len(plan))
+	ready := make(chan int, len(plan))
+	done := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ready {
+				ls := make(labels.LabelSet)
+				for _, d := range deps[i].transitive {
+					if results[d] != nil {
+						ls[results[d].Key] = *results[d]
+					}
+				}
+				results[i], errs[i] = applyRule(c, plan[i], ls)
+				done <- i
+			}
+		}()
+	}
+
+	for i := range plan {
+		if pendingDeps[i] == 0 {
+			ready <- i
+		}
+	}
+	for finished := 0; finished < len(plan); finished++ {
+		i := <-done
+		for _, d := range dependents[i] {
+			pendingDeps[d]--
+			if pendingDeps[d] == 0 {
+				ready <- d
+			}
+		}
+	}
+	close(ready)
+	wg.Wait()
+
 	ls := make(labels.LabelSet)
+	for _, label := range results {
+		if label != nil {
+			ls[label.Key] = *label
+		}
+	}
+	return ls, errors.Join(errs...)
+}
+
+// applyRule returns the label the rule produces, or nil if the label is not valid.
+// Errors returned by rules only mean they don't apply, but a rule that panics is a bug,
+// which is returned as an error with the ID of the rule.
+func applyRule(c codebase.Codebase, r labels.Rule, ls labels.LabelSet) (result *labels.Label, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			result, err = nil, fmt.Errorf("rule %q panicked: %v", r.ID, p)
+		}
+	}()
+
+	label, err := r.Run(c, ls)
+	if err != nil || !label.Valid {
+		return nil, nil
+	}
+	label.Evidence.Rule = r.ID
+	return &label, nil
+}
+
+type ruleDependencies struct {
+	direct     []int // plan indices of the rules producing the keys in DependsOn
+	transitive []int // direct, plus their own dependencies, in plan order
+}
+
+// planDependencies returns the dependencies of each rule in plan
+func planDependencies(plan []labels.Rule) []ruleDependencies {
+	producers := make(map[string][]int)
+	for i, r := range plan {
+		for _, key := range r.Produces {
+			producers[key] = append(producers[key], i)
+		}
+	}
+
+	deps := make([]ruleDependencies, len(plan))
+	for i, r := range plan {
+		isDep := make(map[int]bool)
+		for _, key := range r.DependsOn {
+			for _, p := range producers[key] {
+				// a rule can depend on keys it produces itself
+				if p == i || isDep[p] {
+					continue
+				}
+				isDep[p] = true
+				deps[i].direct = append(deps[i].direct, p)
+				// dependencies come earlier in the plan, so theirs are already known
+				for _, t := range deps[p].transitive {
+					isDep[t] = true
+				}
+			}
+		}
+		for j := 0; j < i; j++ {
+			if isDep[j] {
+				deps[i].transitive = append(deps[i].transitive, j)
+			}
+		}
+	}
+	return deps
+}
+
+// Plan orders rules so that every rule comes after all the rules producing the keys
+// it depends on. Otherwise, rules keep the order they're given in.
+// Dependencies on keys that no rule produces are ignored. It returns an error if rule
+// IDs are not unique or if there are dependency cycles.
+func Plan(rules []labels.Rule) ([]labels.Rule, error) {
+	producers := make(map[string][]string) // label key to the IDs of the rules producing it
+	seenIDs := make(map[string]bool)
 	for _, r := range rules {
-		label, err := r(c, ls)
-		if err != nil {
-			continue
+		if seenIDs[r.ID] {
+			return nil, fmt.Errorf("duplicate rule ID %q", r.ID)
+		}
+		seenIDs[r.ID] = true
+		for _, key := range r.Produces {
+			producers[key] = append(producers[key], r.ID)
 		}
+	}
 
-		if label.Valid {
-			ls[label.Key] = label
+	plan := make([]labels.Rule, 0, len(rules))
+	planned := make(map[string]bool)
+	isReady := func(r labels.Rule) bool {
+		for _, key := range r.DependsOn {
+			for _, id := range producers[key] {
+				if !planned[id] && id != r.ID {
+					return false
+				}
+			}
 		}
+		return true
 	}
 
-	return ls
+	for len(plan) < len(rules) {
+		progress := false
+		for _, r := range rules {
+			if planned[r.ID] || !isReady(r) {
+				continue
+			}
+			plan = append(plan, r)
+			planned[r.ID] = true
+			progress = true
+			// start over, so rules are kept in order unless dependencies force otherwise
+			break
+		}
+		if !progress {
+			var unplanned []string
+			for _, r := range rules {
+				if !planned[r.ID] {
+					unplanned = append(unplanned, r.ID)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle between rules %s", strings.Join(unplanned, ", "))
+		}
+	}
+
+	return plan, nil
 }
 
-func ApplyAllRules(c codebase.Codebase) labels.LabelSet {
+// AllRules returns the rules for all supported stacks
+func AllRules() []labels.Rule {
 	allStacks := [][]labels.Rule{
 		internal.GoRules,
 		internal.JavaRules,
 		internal.NodeRules,
+		internal.DenoRules,
 		internal.PythonRules,
 		internal.RubyRules,
 		internal.RustRules,
 		internal.PhpRules,
+		internal.DotnetRules,
+		internal.ElixirRules,
+		internal.SwiftRules,
+		internal.AndroidRules,
+		internal.ScalaRules,
+		internal.ClojureRules,
+		internal.CppRules,
+		internal.BazelRules,
+		internal.LintRules,
+		internal.DockerRules,
 		internal.GithubActionRules,
 		internal.GitlabWorkflowRules,
 		internal.JenkinsRules,
 		internal.EmptyRepoRules,
+		internal.GitSubmoduleRules,
 		// Add other stacks here
 	}
 
@@ -46,5 +245,101 @@ func ApplyAllRules(c codebase.Codebase) labels.LabelSet {
 	for _, stack := range allStacks {
 		allRules = append(allRules, stack...)
 	}
-	return ApplyRules(c, allRules)
+	return allRules
+}
+
+// WithoutRules returns rules except for the ones with the given IDs
+func WithoutRules(rules []labels.Rule, ids ...string) []labels.Rule {
+	disabled := make(map[string]bool)
+	for _, id := range ids {
+		disabled[id] = true
+	}
+
+	var enabled []labels.Rule
+	for _, r := range rules {
+		if !disabled[r.ID] {
+			enabled = append(enabled, r)
+		}
+	}
+	return enabled
+}
+
+// PrefetchFiles returns the globs of the files the rules of AllRules read, for codebases
+// that can fetch several files at once, like codebase.RemoteCodebase.Prefetch
+func PrefetchFiles() []string {
+	return internal.ReadFiles()
+}
+
+// ApplyAllRules applies AllRules, which can always be planned. It panics if one of them
+// panics, with the ID of the rule.
+func ApplyAllRules(c codebase.Codebase) labels.LabelSet {
+	ls, err := ApplyRules(c, AllRules())
+	if err != nil {
+		panic(err)
+	}
+	return ls
+}
+
+// ApplyAllRulesByProject labels each of the projects in a codebase separately, so that a
+// codebase with e.g. two package.json files gets a deps:node label for each.
+// Projects are rooted at the dirs with a dependency manifest, like package.json or go.mod,
+// and don't include the files of the projects nested in them. The first project is always
+// the one at the root of the codebase, which also gets the labels that are not specific to
+// a project, like cicd:github-actions.
+// If there is only one project, it is labeled like ApplyAllRules would.
+// It returns an error with the IDs of the rules that panicked, like ApplyRules.
+func ApplyAllRulesByProject(c codebase.Codebase) ([]labels.Project, error) {
+	// projects share the files read from c
+	c = codebase.NewCachingCodebase(c)
+	allRoots := internal.ProjectRoots(c)
+	if len(allRoots) <= 1 {
+		ls, err := ApplyRules(c, AllRules())
+		return []labels.Project{{BasePath: ".", Labels: ls}}, err
+	}
+
+	var roots []string
+	for _, root := range allRoots {
+		if root != "." {
+			roots = append(roots, root)
+		}
+	}
+
+	// the codebase is not empty, as it has several projects
+	rules := WithoutRules(AllRules(), "cicd/empty")
+	ls, err := ApplyRules(codebase.SubCodebase{Parent: c, Dir: ".", Exclude: roots}, rules)
+	errs := []error{err}
+	projects := []labels.Project{{BasePath: ".", Labels: ls}}
+	for _, root := range roots {
+		var nested []string
+		for _, other := range roots {
+			if other != root && strings.HasPrefix(other, root+"/") {
+				nested = append(nested, other)
+			}
+		}
+		sub := codebase.SubCodebase{Parent: c, Dir: root, Exclude: nested}
+		ls, err := ApplyRules(sub, rules)
+		errs = append(errs, err)
+		projects = append(projects, labels.Project{
+			BasePath: root,
+			Labels:   inDir(root, ls),
+		})
+	}
+	return projects, errors.Join(errs...)
+}
+
+// inDir makes the paths of labels for a SubCodebase relative to its parent, by joining
+// them with the dir of the SubCodebase
+func inDir(dir string, ls labels.LabelSet) labels.LabelSet {
+	for k, label := range ls {
+		if label.BasePath != "" {
+			label.BasePath = path.Join(dir, label.BasePath)
+		}
+		var files []string
+		for _, f := range label.Evidence.Files {
+			files = append(files, path.Join(dir, f))
+		}
+		label.Evidence.Files = files
+		ls[k] = label
+	}
+	return ls
 }
@@ -1,7 +1,6 @@
 package labeling
 
 import (
-	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -10,35 +9,23 @@ import (
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
 )
 
-// a codebase.Codebase for testing that just reads filenames and contents from a map
-type fakeCodebase struct {
-	// map of filename to file contents
-	contentsByPath map[string]string
-}
-
-func (c fakeCodebase) FindFileMatching(predicate func(string) bool, globs ...string) (string, error) {
-	for _, g := range globs {
-		for path := range c.contentsByPath {
-			matchesName, _ := filepath.Match(g, filepath.Base(path))
-			matchesPath, _ := filepath.Match(g, path)
-			if (matchesName || matchesPath) && predicate(path) {
-				return path, nil
-			}
-		}
+// withoutEvidence clears the Evidence of all labels, for tests that only check LabelData
+func withoutEvidence(ls labels.LabelSet) labels.LabelSet {
+	for k, label := range ls {
+		label.Evidence = labels.Evidence{}
+		ls[k] = label
 	}
-	return "", codebase.NotFoundError
+	return ls
 }
 
-func (c fakeCodebase) FindFile(globs ...string) (path string, err error) {
-	return c.FindFileMatching(func(string) bool { return true }, globs...)
-}
-
-func (c fakeCodebase) ReadFile(path string) (contents []byte, err error) {
-	contentString := c.contentsByPath[path]
-	if contentString != "" {
-		return []byte(contentString), nil
+// applyRules applies rules to c, failing the test if they can't be applied
+func applyRules(t *testing.T, c codebase.Codebase, rules []labels.Rule) labels.LabelSet {
+	t.Helper()
+	ls, err := ApplyRules(c, rules)
+	if err != nil {
+		t.Fatalf("ApplyRules: %v", err)
 	}
-	return nil, codebase.NotFoundError
+	return ls
 }
 
 func TestCodebase_ApplyAllRules(t *testing.T) {
@@ -84,14 +71,14 @@ func TestCodebase_ApplyAllRules(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := fakeCodebase{tt.files}
+			c := codebase.MapCodebase(tt.files)
 			expected := make(labels.LabelSet)
 			for _, label := range tt.expectedLabels {
 				// all should be Valid
 				label.Valid = true
 				expected[label.Key] = label
 			}
-			got := ApplyAllRules(c)
+			got := withoutEvidence(ApplyAllRules(c))
 
 			if !reflect.DeepEqual(got, expected) {
 				t.Errorf("\n"+
@@ -104,6 +91,44 @@ func TestCodebase_ApplyAllRules(t *testing.T) {
 	}
 }
 
+func TestCodebase_ApplyAllRules_Evidence(t *testing.T) {
+	c := codebase.MapCodebase(map[string]string{
+		"go.mod":           "",
+		"go.sum":           "",
+		"cmd/cmd.go":       "package main",
+		"web/package.json": `{"devDependencies":{"jest": "version"}}`,
+	})
+	expected := map[string]labels.Evidence{
+		labels.DepsGo: {
+			Rule:  "go/deps",
+			Files: []string{"go.mod", "go.sum"},
+		},
+		labels.ArtifactGoExecutable: {
+			Rule:  "go/executable",
+			Files: []string{"cmd/cmd.go"},
+		},
+		labels.DepsNode: {
+			Rule:  "node/deps",
+			Files: []string{"web/package.json"},
+		},
+		labels.TestJest: {
+			Rule:         "node/jest",
+			Files:        []string{"web/package.json"},
+			Dependencies: []string{"jest"},
+		},
+	}
+
+	got := ApplyAllRules(c)
+	if len(got) != len(expected) {
+		t.Errorf("got labels %v, expected %d labels", got, len(expected))
+	}
+	for key, evidence := range expected {
+		if !reflect.DeepEqual(got[key].Evidence, evidence) {
+			t.Errorf("%s: got evidence %+v, expected %+v", key, got[key].Evidence, evidence)
+		}
+	}
+}
+
 func TestCodebase_ApplyRules_Node(t *testing.T) {
 	rules := internal.NodeRules
 	tests := []struct {
@@ -250,14 +275,14 @@ func TestCodebase_ApplyRules_Node(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := fakeCodebase{tt.files}
+			c := codebase.MapCodebase(tt.files)
 			expected := make(labels.LabelSet)
 			for _, label := range tt.expectedLabels {
 				// all should be Valid
 				label.Valid = true
 				expected[label.Key] = label
 			}
-			got := ApplyRules(c, rules)
+			got := withoutEvidence(applyRules(t, c, rules))
 
 			if !reflect.DeepEqual(got, expected) {
 				t.Errorf("\n"+
@@ -375,14 +400,14 @@ func TestCodebase_ApplyRules_Go(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := fakeCodebase{tt.files}
+			c := codebase.MapCodebase(tt.files)
 			expected := make(labels.LabelSet)
 			for _, label := range tt.expectedLabels {
 				// all should be Valid
 				label.Valid = true
 				expected[label.Key] = label
 			}
-			got := ApplyRules(c, rules)
+			got := withoutEvidence(applyRules(t, c, rules))
 
 			if !reflect.DeepEqual(got, expected) {
 				t.Errorf("\n"+
@@ -635,14 +660,14 @@ func TestCodebase_ApplyRules_Python(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := fakeCodebase{tt.files}
+			c := codebase.MapCodebase(tt.files)
 			expected := make(labels.LabelSet)
 			for _, label := range tt.expectedLabels {
 				// all should be Valid
 				label.Valid = true
 				expected[label.Key] = label
 			}
-			got := ApplyRules(c, rules)
+			got := withoutEvidence(applyRules(t, c, rules))
 
 			if !reflect.DeepEqual(got, expected) {
 				t.Errorf("\n"+
@@ -734,14 +759,14 @@ func TestCodebase_ApplyRules_Java(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := fakeCodebase{tt.files}
+			c := codebase.MapCodebase(tt.files)
 			expected := make(labels.LabelSet)
 			for _, label := range tt.expectedLabels {
 				// all should be Valid
 				label.Valid = true
 				expected[label.Key] = label
 			}
-			got := ApplyRules(c, rules)
+			got := withoutEvidence(applyRules(t, c, rules))
 
 			if !reflect.DeepEqual(got, expected) {
 				t.Errorf("\n"+
@@ -836,13 +861,48 @@ func TestCodebase_ApplyRules_CICD(t *testing.T) {
 			rules:    internal.JenkinsRules,
 			expected: labels.LabelSet{},
 		},
+
+		{
+			name: "git submodules",
+			files: map[string]string{
+				".gitmodules": "[submodule \"shared\"]\n" +
+					"\tpath = libs/shared\n" +
+					"\turl = https://github.com/example/shared.git\n" +
+					"# comment\n" +
+					"[submodule \"docs\"]\n" +
+					"\tbranch = main\n" +
+					"\tpath = docs\n" +
+					"\turl = ../docs.git\n",
+			},
+			rules: internal.GitSubmoduleRules,
+			expected: labels.LabelSet{
+				labels.VCSGitSubmodules: labels.Label{
+					Key:   labels.VCSGitSubmodules,
+					Valid: true,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Dependencies: map[string]string{
+							"libs/shared": "https://github.com/example/shared.git",
+							"docs":        "../docs.git",
+						},
+					},
+				},
+			},
+		},
+
+		{
+			name:     "no git submodules",
+			files:    map[string]string{".gitmodules": ""},
+			rules:    internal.GitSubmoduleRules,
+			expected: labels.LabelSet{},
+		},
 	}
 
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			c := fakeCodebase{tt.files}
-			got := ApplyRules(c, tt.rules)
+			c := codebase.MapCodebase(tt.files)
+			got := withoutEvidence(applyRules(t, c, tt.rules))
 
 			if !reflect.DeepEqual(got, tt.expected) {
 				t.Errorf("\n"+
@@ -860,8 +920,8 @@ func TestCodebase_ApplyRules_Empty(t *testing.T) {
 	t.Run("no files", func(t *testing.T) {
 		repo := map[string]string{}
 		rules := internal.EmptyRepoRules
-		c := fakeCodebase{repo}
-		got := ApplyRules(c, rules)
+		c := codebase.MapCodebase(repo)
+		got := withoutEvidence(applyRules(t, c, rules))
 		want := labels.LabelSet{
 			labels.EmptyRepo: labels.Label{
 				Key:   labels.EmptyRepo,
@@ -880,8 +940,8 @@ func TestCodebase_ApplyRules_Empty(t *testing.T) {
 	t.Run("only has readme", func(t *testing.T) {
 		repo := map[string]string{"README.md": "#hello world", ".git/refs/ref1": "something", ".git": "something"}
 		rules := internal.EmptyRepoRules
-		c := fakeCodebase{repo}
-		got := ApplyRules(c, rules)
+		c := codebase.MapCodebase(repo)
+		got := withoutEvidence(applyRules(t, c, rules))
 		want := labels.LabelSet{
 			labels.EmptyRepo: labels.Label{
 				Key:   labels.EmptyRepo,
@@ -900,8 +960,8 @@ func TestCodebase_ApplyRules_Empty(t *testing.T) {
 	t.Run("has readme but with different casing", func(t *testing.T) {
 		repo := map[string]string{"readME.md": "#hello world"}
 		rules := internal.EmptyRepoRules
-		c := fakeCodebase{repo}
-		got := ApplyRules(c, rules)
+		c := codebase.MapCodebase(repo)
+		got := withoutEvidence(applyRules(t, c, rules))
 		want := labels.LabelSet{
 			labels.EmptyRepo: labels.Label{
 				Key:   labels.EmptyRepo,
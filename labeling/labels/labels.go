@@ -9,28 +9,77 @@ import (
 )
 
 const (
-	ArtifactGoExecutable  = "artifact:go-executable"
-	ArtifactRustCrate     = "artifact:rust-crate"
-	DepsGo                = "deps:go"
-	DepsJava              = "deps:java"
-	DepsNode              = "deps:node"
-	DepsPython            = "deps:python"
-	DepsRuby              = "deps:ruby"
-	DepsRust              = "deps:rust"
-	DepsPhp               = "deps:php"
-	PackageManagerPipenv  = "package_manager:pipenv"
-	PackageManagerPoetry  = "package_manager:poetry"
-	PackageManagerYarn    = "package_manager:yarn"
-	PackageManagerGemspec = "package_manager:gemspec"
-	CICDGithubActions     = "cicd:github-actions"
-	CICDGitlabWorkflow    = "cicd:gitlab-workflows"
-	CICDJenkins           = "cicd:jenkins"
-	EmptyRepo             = "cicd:empty"
-	TestJest              = "test:jest"
-	ToolGradle            = "tool:gradle"
-	FileManagePy          = "file:manage.py"
-	FileSetupPy           = "file:setup.py"
-	TestTox               = "test:tox"
+	ArtifactGoExecutable     = "artifact:go-executable"
+	ArtifactRustCrate        = "artifact:rust-crate"
+	ArtifactSbtAssembly      = "artifact:sbt-assembly"
+	ArtifactLeinUberjar      = "artifact:lein-uberjar"
+	ArtifactDockerImage      = "artifact:docker-image"
+	ArtifactDotnetExecutable = "artifact:dotnet-executable"
+	BuildBazel               = "build:bazel"
+	BuildCMake               = "build:cmake"
+	BuildMake                = "build:make"
+	BuildMeson               = "build:meson"
+	DepsGo                   = "deps:go"
+	DepsJava                 = "deps:java"
+	DepsNode                 = "deps:node"
+	DepsDeno                 = "deps:deno"
+	DepsPython               = "deps:python"
+	DepsRuby                 = "deps:ruby"
+	DepsRust                 = "deps:rust"
+	DepsPhp                  = "deps:php"
+	DepsDotnet               = "deps:dotnet"
+	DepsElixir               = "deps:elixir"
+	DepsErlang               = "deps:erlang"
+	DepsSwift                = "deps:swift"
+	DepsAndroid              = "deps:android"
+	DepsKotlinMultiplatform  = "deps:kotlin-multiplatform"
+	DepsScala                = "deps:scala"
+	DepsClojure              = "deps:clojure"
+	PackageManagerPipenv     = "package_manager:pipenv"
+	PackageManagerPoetry     = "package_manager:poetry"
+	PackageManagerYarn       = "package_manager:yarn"
+	PackageManagerGemspec    = "package_manager:gemspec"
+	PackageManagerCocoapods  = "package_manager:cocoapods"
+	PackageManagerLein       = "package_manager:leiningen"
+	PackageManagerConan      = "package_manager:conan"
+	PackageManagerVcpkg      = "package_manager:vcpkg"
+	PackageManagerBun        = "package_manager:bun"
+	PackageManagerPnpm       = "package_manager:pnpm"
+	CICDGithubActions        = "cicd:github-actions"
+	CICDGitlabWorkflow       = "cicd:gitlab-workflows"
+	CICDJenkins              = "cicd:jenkins"
+	EmptyRepo                = "cicd:empty"
+	TestJest                 = "test:jest"
+	TestVitest               = "test:vitest"
+	TestMocha                = "test:mocha"
+	TestAva                  = "test:ava"
+	TestPlaywright           = "test:playwright"
+	TestCypress              = "test:cypress"
+	ToolGradle               = "tool:gradle"
+	ToolXcode                = "tool:xcode"
+	ToolFastlane             = "tool:fastlane"
+	ToolTurborepo            = "tool:turborepo"
+	ToolNx                   = "tool:nx"
+	ToolLerna                = "tool:lerna"
+	WorkspacesNode           = "workspaces:node"
+	LintESLint               = "lint:eslint"
+	LintPrettier             = "lint:prettier"
+	LintGolangci             = "lint:golangci-lint"
+	LintRuff                 = "lint:ruff"
+	LintFlake8               = "lint:flake8"
+	LintBlack                = "lint:black"
+	LintRubocop              = "lint:rubocop"
+	LintPhpcs                = "lint:phpcs"
+	LintPhpstan              = "lint:phpstan"
+	LintClippy               = "lint:clippy"
+	LintRustfmt              = "lint:rustfmt"
+	LintCheckstyle           = "lint:checkstyle"
+	LintSpotless             = "lint:spotless"
+	FileManagePy             = "file:manage.py"
+	FileSetupPy              = "file:setup.py"
+	TestTox                  = "test:tox"
+	TestDotnet               = "test:dotnet"
+	VCSGitSubmodules         = "vcs:git-submodules"
 )
 
 type LabelData struct {
@@ -41,11 +90,39 @@ type LabelData struct {
 	Version      string
 }
 
+// Evidence records why a Rule applied a label
+type Evidence struct {
+	Rule         string   // identifier of the rule that applied the label, like "go/deps"
+	Files        []string // paths of the files the rule matched
+	Dependencies []string // names of the dependencies the rule matched
+}
+
+func (e Evidence) String() string {
+	s := fmt.Sprintf("rule %s", e.Rule)
+	if len(e.Files) > 0 {
+		s += fmt.Sprintf(", files: %s", strings.Join(e.Files, ", "))
+	}
+	if len(e.Dependencies) > 0 {
+		s += fmt.Sprintf(", dependencies: %s", strings.Join(e.Dependencies, ", "))
+	}
+	return s
+}
+
 // Label is the result of applying a Rule
 type Label struct {
 	Key       string // string identifying the label, like "deps:go"
 	Valid     bool   // If the rule applies, Valid = true
 	LabelData        // LabelData rule-specific data for each label
+	Evidence  Evidence
+}
+
+// AddFiles records paths as evidence for the label, skipping empty paths
+func (label *Label) AddFiles(paths ...string) {
+	for _, p := range paths {
+		if p != "" {
+			label.Evidence.Files = append(label.Evidence.Files, p)
+		}
+	}
 }
 
 func (label Label) String() string {
@@ -56,6 +133,9 @@ func (label Label) String() string {
 	}
 }
 
+// LabelSet maps label keys to labels. Like any map, it is safe for concurrent reads but
+// not for concurrent writes: when applying rules concurrently, each rule gets its own
+// LabelSet, which it should treat as read-only.
 type LabelSet map[string]Label
 
 func (ls LabelSet) String() string {
@@ -69,4 +149,34 @@ func (ls LabelSet) String() string {
 	return strings.Join(labelsAsStrings, ",")
 }
 
-type Rule func(codebase.Codebase, LabelSet) (Label, error)
+// Project holds the labels of one of the projects in a codebase, e.g. a package of a
+// monorepo. A codebase with several projects is labeled with a []Project, which can hold
+// several instances of a key, each with its own BasePath.
+type Project struct {
+	BasePath string // dir of the project in the codebase, "." for the codebase root
+	Labels   LabelSet
+}
+
+// Name returns a name for the project that can be used in job names, like "web-admin"
+// for BasePath "web/admin"
+func (p Project) Name() string {
+	if p.BasePath == "." || p.BasePath == "" {
+		return "root"
+	}
+	return strings.ReplaceAll(p.BasePath, "/", "-")
+}
+
+// Rule labels a codebase. Rules declare the label keys they produce and the keys they
+// depend on, i.e. that Run reads from the LabelSet it is given, so they can be ordered
+// so that dependencies are applied first.
+// Rules run concurrently, and the LabelSet Run is given only has the labels of the keys in
+// DependsOn, and of the keys those rules depend on. Reading any other key gets a zero
+// Label, even if a rule applied earlier produced it, so every key Run reads must be
+// declared.
+type Rule struct {
+	ID          string   // unique identifier, like "go/deps"
+	Description string   // what the rule looks for, in a few words
+	Produces    []string // keys of the labels Run returns
+	DependsOn   []string // keys of the labels Run reads, the only ones it is given
+	Run         func(codebase.Codebase, LabelSet) (Label, error)
+}
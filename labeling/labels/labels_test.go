@@ -69,3 +69,33 @@ func TestLabelSet_String(t *testing.T) {
 		})
 	}
 }
+
+func TestEvidence_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		evidence Evidence
+		expected string
+	}{
+		{
+			name:     "rule only",
+			evidence: Evidence{Rule: "cicd/empty"},
+			expected: "rule cicd/empty",
+		}, {
+			name: "files and dependencies",
+			evidence: Evidence{
+				Rule:         "node/jest",
+				Files:        []string{"package.json"},
+				Dependencies: []string{"jest"},
+			},
+			expected: "rule node/jest, files: package.json, dependencies: jest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.evidence.String()
+			if got != tt.expected {
+				t.Errorf("String() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
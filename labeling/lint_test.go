@@ -0,0 +1,122 @@
+package labeling
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyLintRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		expected  []string
+		notLinted []string
+	}{
+		{
+			name: "ESLint flat config and Prettier",
+			files: map[string]string{
+				"package.json":      "{}",
+				"eslint.config.mjs": "",
+				".prettierrc":       "{}",
+			},
+			expected: []string{labels.LintESLint, labels.LintPrettier},
+		},
+		{
+			name: "ESLint config in package.json",
+			files: map[string]string{
+				"package.json": `{"eslintConfig": {"extends": "react-app"}}`,
+			},
+			expected:  []string{labels.LintESLint},
+			notLinted: []string{labels.LintPrettier},
+		},
+		{
+			name: "golangci-lint",
+			files: map[string]string{
+				"go.mod":        "module example.com/x",
+				".golangci.yml": "linters: {}",
+			},
+			expected: []string{labels.LintGolangci},
+		},
+		{
+			name: "ruff and black in pyproject.toml, flake8 in setup.cfg",
+			files: map[string]string{
+				"pyproject.toml": "[tool.ruff.lint]\nselect = [\"E\"]\n\n[tool.black]\nline-length = 100\n",
+				"setup.cfg":      "[flake8]\nmax-line-length = 100\n",
+			},
+			expected: []string{labels.LintRuff, labels.LintBlack, labels.LintFlake8},
+		},
+		{
+			name: "RuboCop",
+			files: map[string]string{
+				"Gemfile":      "source 'https://rubygems.org'",
+				".rubocop.yml": "",
+			},
+			expected: []string{labels.LintRubocop},
+		},
+		{
+			name: "PHP_CodeSniffer and PHPStan",
+			files: map[string]string{
+				"composer.json":     "{}",
+				"phpcs.xml.dist":    "",
+				"phpstan.neon.dist": "",
+			},
+			expected: []string{labels.LintPhpcs, labels.LintPhpstan},
+		},
+		{
+			name: "clippy lints in Cargo.toml and rustfmt",
+			files: map[string]string{
+				"Cargo.toml":   "[package]\nname = \"x\"\n\n[lints.clippy]\npedantic = \"warn\"\n",
+				"rustfmt.toml": "",
+			},
+			expected: []string{labels.LintClippy, labels.LintRustfmt},
+		},
+		{
+			name: "Checkstyle and Spotless in gradle",
+			files: map[string]string{
+				"gradlew":      "",
+				"build.gradle": "plugins {\n  id 'checkstyle'\n  id 'com.diffplug.spotless' version '6.25.0'\n}\n",
+			},
+			expected: []string{labels.LintCheckstyle, labels.LintSpotless},
+		},
+		{
+			name: "linter config without its stack",
+			files: map[string]string{
+				".golangci.yml": "",
+				".rubocop.yml":  "",
+			},
+			notLinted: []string{labels.LintGolangci, labels.LintRubocop},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyAllRules(codebase.MapCodebase(tt.files))
+			for _, key := range tt.expected {
+				if !got[key].Valid {
+					t.Errorf("expected a valid %s label", key)
+				}
+			}
+			for _, key := range tt.notLinted {
+				if _, ok := got[key]; ok {
+					t.Errorf("got %s, expected no label", got[key])
+				}
+			}
+			if d := cmp.Diff(len(tt.expected), countLintLabels(got)); d != "" {
+				t.Errorf("lint labels count mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func countLintLabels(ls labels.LabelSet) (n int) {
+	for key := range ls {
+		if strings.HasPrefix(key, "lint:") {
+			n++
+		}
+	}
+	return n
+}
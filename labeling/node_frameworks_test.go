@@ -0,0 +1,57 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyNodeTestFrameworkRules(t *testing.T) {
+	files := map[string]string{
+		"web/package.json": `{
+  "devDependencies": {
+    "vitest": "^1.2.0",
+    "mocha": "^10.2.0",
+    "ava": "^6.0.0",
+    "@playwright/test": "^1.40.1",
+    "cypress": "13.6.0"
+  }
+}`,
+	}
+	dependencies := map[string]string{
+		"vitest":           "^1.2.0",
+		"mocha":            "^10.2.0",
+		"ava":              "^6.0.0",
+		"@playwright/test": "^1.40.1",
+		"cypress":          "13.6.0",
+	}
+	expected := labels.LabelSet{
+		labels.DepsNode: {
+			Key:       labels.DepsNode,
+			LabelData: labels.LabelData{BasePath: "web", Dependencies: dependencies},
+		},
+	}
+	for key, dep := range map[string]string{
+		labels.TestVitest:     "vitest",
+		labels.TestMocha:      "mocha",
+		labels.TestAva:        "ava",
+		labels.TestPlaywright: "@playwright/test",
+		labels.TestCypress:    "cypress",
+	} {
+		expected[key] = labels.Label{
+			Key:       key,
+			LabelData: labels.LabelData{BasePath: "web", Version: dependencies[dep]},
+		}
+	}
+	for key, label := range expected {
+		label.Valid = true
+		expected[key] = label
+	}
+
+	got := withoutEvidence(ApplyAllRules(codebase.MapCodebase(files)))
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+	}
+}
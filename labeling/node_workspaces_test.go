@@ -0,0 +1,158 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyNodeWorkspacesRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "pnpm workspace with Turborepo",
+			files: map[string]string{
+				"package.json":          `{"packageManager": "pnpm@8.15.0+sha256.abc", "scripts": {"build": "turbo run build"}}`,
+				"pnpm-lock.yaml":        "",
+				"pnpm-workspace.yaml":   "packages:\n  - apps/*\n",
+				"turbo.json":            "{}",
+				"apps/web/package.json": `{"scripts": {"test": "vitest"}}`,
+				"apps/web/turbo.json":   "{}",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsNode,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						HasLockFile:  true,
+						Dependencies: map[string]string{},
+						Tasks:        map[string]string{"build": "turbo run build"},
+					},
+				},
+				{
+					Key:       labels.PackageManagerPnpm,
+					LabelData: labels.LabelData{BasePath: ".", Version: "8.15.0"},
+				},
+				{
+					Key:       labels.WorkspacesNode,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+				{
+					Key:       labels.ToolTurborepo,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+			},
+		},
+		{
+			name: "pnpm files of fixtures",
+			files: map[string]string{
+				"package.json":                 "{}",
+				"package-lock.json":            "{}",
+				"test/fixtures/pnpm-lock.yaml": "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsNode,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						HasLockFile:  true,
+						Dependencies: map[string]string{},
+					},
+				},
+			},
+		},
+		{
+			name: "npm workspaces with Nx and Lerna",
+			files: map[string]string{
+				"package.json":            `{"workspaces": ["packages/*"]}`,
+				"package-lock.json":       "{}",
+				"nx.json":                 "{}",
+				"lerna.json":              "{}",
+				"packages/a/package.json": "{}",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsNode,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						HasLockFile:  true,
+						Dependencies: map[string]string{},
+					},
+				},
+				{
+					Key:       labels.WorkspacesNode,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+				{
+					Key:       labels.ToolNx,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+				{
+					Key:       labels.ToolLerna,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+			},
+		},
+		{
+			name: "yarn workspaces object",
+			files: map[string]string{
+				"package.json": `{"workspaces": {"packages": ["packages/*"]}}`,
+				"yarn.lock":    "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsNode,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						HasLockFile:  true,
+						Dependencies: map[string]string{},
+					},
+				},
+				{
+					Key:       labels.PackageManagerYarn,
+					LabelData: labels.LabelData{Version: "classic"},
+				},
+				{
+					Key:       labels.WorkspacesNode,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+			},
+		},
+		{
+			name: "empty workspaces",
+			files: map[string]string{
+				"package.json": `{"workspaces": []}`,
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsNode,
+					LabelData: labels.LabelData{
+						BasePath:     ".",
+						Dependencies: map[string]string{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
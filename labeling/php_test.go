@@ -3,6 +3,7 @@ package labeling
 import (
 	"testing"
 
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
 	"github.com/CircleCI-Public/circleci-config/labeling/labels"
 	"github.com/google/go-cmp/cmp"
 )
@@ -37,14 +38,14 @@ func TestCodebase_ApplyPhpRules(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := fakeCodebase{tt.files}
+			c := codebase.MapCodebase(tt.files)
 			expected := make(labels.LabelSet)
 			for _, label := range tt.expectedLabels {
 				// all should be Valid
 				label.Valid = true
 				expected[label.Key] = label
 			}
-			got := ApplyAllRules(c)
+			got := withoutEvidence(ApplyAllRules(c))
 			if diff := cmp.Diff(expected, got); diff != "" {
 				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
 			}
@@ -0,0 +1,249 @@
+package labeling
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testRule returns a rule that produces a valid label with key produces
+func testRule(id string, produces string, dependsOn ...string) labels.Rule {
+	return labels.Rule{
+		ID:        id,
+		Produces:  []string{produces},
+		DependsOn: dependsOn,
+		Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+			return labels.Label{Key: produces, Valid: true}, nil
+		},
+	}
+}
+
+func ruleIDs(rules []labels.Rule) []string {
+	ids := make([]string, len(rules))
+	for i, r := range rules {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       []labels.Rule
+		expectedIDs []string
+		expectErr   bool
+	}{
+		{
+			name: "no dependencies keeps order",
+			rules: []labels.Rule{
+				testRule("b", "label:b"),
+				testRule("a", "label:a"),
+			},
+			expectedIDs: []string{"b", "a"},
+		}, {
+			name: "dependency declared later comes first",
+			rules: []labels.Rule{
+				testRule("test", "test:x", "deps:x"),
+				testRule("other", "other:y"),
+				testRule("deps", "deps:x"),
+			},
+			expectedIDs: []string{"other", "deps", "test"},
+		}, {
+			name: "transitive dependencies",
+			rules: []labels.Rule{
+				testRule("c", "label:c", "label:b"),
+				testRule("b", "label:b", "label:a"),
+				testRule("a", "label:a"),
+			},
+			expectedIDs: []string{"a", "b", "c"},
+		}, {
+			name: "dependency on a key no rule produces is ignored",
+			rules: []labels.Rule{
+				testRule("test", "test:x", "deps:x"),
+			},
+			expectedIDs: []string{"test"},
+		}, {
+			name: "cycle",
+			rules: []labels.Rule{
+				testRule("a", "label:a", "label:b"),
+				testRule("b", "label:b", "label:a"),
+			},
+			expectErr: true,
+		}, {
+			name: "duplicate IDs",
+			rules: []labels.Rule{
+				testRule("a", "label:a"),
+				testRule("a", "label:b"),
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Plan(tt.rules)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Plan() error %v, expectErr %v", err, tt.expectErr)
+				return
+			}
+			if d := cmp.Diff(tt.expectedIDs, ruleIDs(got)); !tt.expectErr && d != "" {
+				t.Errorf("Plan() mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestPlan_AllRules(t *testing.T) {
+	plan, err := Plan(AllRules())
+	if err != nil {
+		t.Fatalf("got error %v, expected AllRules to be plannable", err)
+	}
+	if len(plan) != len(AllRules()) {
+		t.Errorf("got %d rules planned, expected %d", len(plan), len(AllRules()))
+	}
+	for _, r := range plan {
+		if r.ID == "" || len(r.Produces) == 0 || r.Run == nil {
+			t.Errorf("rule %q is missing an ID, Produces or Run", r.ID)
+		}
+	}
+}
+
+func TestWithoutRules(t *testing.T) {
+	rules := []labels.Rule{
+		testRule("a", "label:a"),
+		testRule("b", "label:b"),
+		testRule("c", "label:c"),
+	}
+	got := ruleIDs(WithoutRules(rules, "b", "unknown"))
+	if d := cmp.Diff([]string{"a", "c"}, got); d != "" {
+		t.Errorf("WithoutRules() mismatch (-want +got):\n%s", d)
+	}
+
+	ls := applyRules(t, codebase.MapCodebase{}, WithoutRules(rules, "a"))
+	if ls["label:a"].Valid || !ls["label:b"].Valid {
+		t.Errorf("got %v, expected only label:b and label:c", ls)
+	}
+}
+
+func TestApplyRulesN(t *testing.T) {
+	t.Run("independent rules run concurrently", func(t *testing.T) {
+		// each rule waits for the other to start, so this only finishes if both run at once
+		var started int32
+		waitForBoth := func(key string) labels.Rule {
+			return labels.Rule{
+				ID:       key,
+				Produces: []string{key},
+				Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+					atomic.AddInt32(&started, 1)
+					deadline := time.Now().Add(5 * time.Second)
+					for atomic.LoadInt32(&started) < 2 {
+						if time.Now().After(deadline) {
+							return labels.Label{}, fmt.Errorf("timed out waiting for the other rule")
+						}
+						time.Sleep(time.Millisecond)
+					}
+					return labels.Label{Key: key, Valid: true}, nil
+				},
+			}
+		}
+
+		got, err := ApplyRulesN(codebase.MapCodebase{}, []labels.Rule{waitForBoth("label:a"), waitForBoth("label:b")}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got["label:a"].Valid || !got["label:b"].Valid {
+			t.Errorf("got %v, expected label:a and label:b", got)
+		}
+	})
+
+	t.Run("rules only see the labels they depend on", func(t *testing.T) {
+		seen := func(id string, dependsOn ...string) labels.Rule {
+			return labels.Rule{
+				ID:        id,
+				Produces:  []string{id},
+				DependsOn: dependsOn,
+				Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+					return labels.Label{
+						Key:       id,
+						Valid:     true,
+						LabelData: labels.LabelData{BasePath: ls.String()},
+					}, nil
+				},
+			}
+		}
+		rules := []labels.Rule{
+			seen("a"),
+			seen("b", "a"),
+			seen("c", "b"),
+			seen("d"),
+		}
+
+		for i := 0; i < 20; i++ {
+			got, err := ApplyRulesN(codebase.MapCodebase{}, rules, 4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := map[string]string{
+				"a": "",
+				"b": "a:",
+				"c": "a:,b:a:",
+				"d": "",
+			}
+			for key, seenLabels := range expected {
+				if got[key].BasePath != seenLabels {
+					t.Errorf("%s saw %q, expected %q", key, got[key].BasePath, seenLabels)
+				}
+			}
+		}
+	})
+
+	t.Run("same key from several rules, last in plan wins", func(t *testing.T) {
+		withVersion := func(id string, version string) labels.Rule {
+			return labels.Rule{
+				ID:       id,
+				Produces: []string{"label:x"},
+				Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+					return labels.Label{Key: "label:x", Valid: true, LabelData: labels.LabelData{Version: version}}, nil
+				},
+			}
+		}
+		for i := 0; i < 20; i++ {
+			got, err := ApplyRulesN(codebase.MapCodebase{}, []labels.Rule{withVersion("first", "1"), withVersion("second", "2")}, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got["label:x"].Version != "2" || got["label:x"].Evidence.Rule != "second" {
+				t.Fatalf("got %+v, expected the label from the second rule", got["label:x"])
+			}
+		}
+	})
+
+	t.Run("rules that can't be planned", func(t *testing.T) {
+		rules := []labels.Rule{testRule("a", "label:a"), testRule("a", "label:b")}
+		if _, err := ApplyRulesN(codebase.MapCodebase{}, rules, 2); err == nil {
+			t.Errorf("got no error, expected one for the duplicate rule ID")
+		}
+	})
+
+	t.Run("rules that panic", func(t *testing.T) {
+		panics := labels.Rule{
+			ID:       "panics",
+			Produces: []string{"label:b"},
+			Run: func(c codebase.Codebase, ls labels.LabelSet) (labels.Label, error) {
+				panic("boom")
+			},
+		}
+		got, err := ApplyRulesN(codebase.MapCodebase{}, []labels.Rule{testRule("a", "label:a"), panics}, 2)
+		if err == nil || !strings.Contains(err.Error(), `rule "panics" panicked`) {
+			t.Errorf("got error %v, expected one with the ID of the rule that panicked", err)
+		}
+		if !got["label:a"].Valid || got["label:b"].Valid {
+			t.Errorf("got %v, expected only the label of the other rule", got)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplyAllRulesByProject(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []labels.Project
+	}{
+		{
+			name: "single project in a subdir",
+			files: map[string]string{
+				"web/package.json": `{}`,
+			},
+			expected: []labels.Project{{
+				BasePath: ".",
+				Labels: labels.LabelSet{
+					labels.DepsNode: {
+						Key:       labels.DepsNode,
+						Valid:     true,
+						LabelData: labels.LabelData{BasePath: "web", Dependencies: map[string]string{}},
+					},
+				},
+			}},
+		}, {
+			name: "two node projects",
+			files: map[string]string{
+				"frontend/package.json":                `{"devDependencies":{"jest": "1.0"}}`,
+				"admin/package.json":                   `{}`,
+				"admin/node_modules/a/package.json":    `{}`,
+				".github/workflows/ci.yml":             "",
+				"frontend/.github/workflows/build.yml": "",
+			},
+			expected: []labels.Project{{
+				BasePath: ".",
+				Labels: labels.LabelSet{
+					labels.CICDGithubActions: {
+						Key:       labels.CICDGithubActions,
+						Valid:     true,
+						LabelData: labels.LabelData{BasePath: ".github/workflows"},
+					},
+				},
+			}, {
+				BasePath: "admin",
+				Labels: labels.LabelSet{
+					labels.DepsNode: {
+						Key:       labels.DepsNode,
+						Valid:     true,
+						LabelData: labels.LabelData{BasePath: "admin", Dependencies: map[string]string{}},
+					},
+				},
+			}, {
+				BasePath: "frontend",
+				Labels: labels.LabelSet{
+					labels.DepsNode: {
+						Key:   labels.DepsNode,
+						Valid: true,
+						LabelData: labels.LabelData{
+							BasePath:     "frontend",
+							Dependencies: map[string]string{"jest": "1.0"},
+						},
+					},
+					labels.TestJest: {
+						Key:   labels.TestJest,
+						Valid: true,
+					},
+					labels.CICDGithubActions: {
+						Key:       labels.CICDGithubActions,
+						Valid:     true,
+						LabelData: labels.LabelData{BasePath: "frontend/.github/workflows"},
+					},
+				},
+			}},
+		}, {
+			name: "go module at the root and a nested node project",
+			files: map[string]string{
+				"go.mod":           "",
+				"main.go":          "package main",
+				"web/package.json": `{}`,
+				"web/tools.go":     "package main",
+			},
+			expected: []labels.Project{{
+				BasePath: ".",
+				Labels: labels.LabelSet{
+					labels.DepsGo: {
+						Key:       labels.DepsGo,
+						Valid:     true,
+						LabelData: labels.LabelData{BasePath: "."},
+					},
+					labels.ArtifactGoExecutable: {
+						Key:   labels.ArtifactGoExecutable,
+						Valid: true,
+					},
+				},
+			}, {
+				BasePath: "web",
+				Labels: labels.LabelSet{
+					labels.DepsNode: {
+						Key:       labels.DepsNode,
+						Valid:     true,
+						LabelData: labels.LabelData{BasePath: "web", Dependencies: map[string]string{}},
+					},
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyAllRulesByProject(codebase.MapCodebase(tt.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range got {
+				withoutEvidence(p.Labels)
+			}
+			if d := cmp.Diff(tt.expected, got); d != "" {
+				t.Errorf("ApplyAllRulesByProject mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestApplyAllRulesByProject_Evidence(t *testing.T) {
+	got, err := ApplyAllRulesByProject(codebase.MapCodebase(map[string]string{
+		"a/go.mod": "",
+		"b/go.mod": "",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d projects, expected 3", len(got))
+	}
+	evidence := got[2].Labels[labels.DepsGo].Evidence
+	if d := cmp.Diff([]string{"b/go.mod"}, evidence.Files); d != "" {
+		t.Errorf("evidence files mismatch (-want +got):\n%s", d)
+	}
+}
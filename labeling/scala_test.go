@@ -0,0 +1,84 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplyScalaRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "sbt project with scalatest and sbt-assembly",
+			files: map[string]string{
+				"build.sbt": `ThisBuild / scalaVersion := "2.13.12"
+
+lazy val root = (project in file("."))
+  .settings(
+    libraryDependencies ++= Seq(
+      "org.typelevel" %% "cats-core" % "2.10.0",
+      "org.scalatest" %% "scalatest" % "3.2.17" % Test
+    )
+  )
+`,
+				"project/plugins.sbt":      `addSbtPlugin("com.eed3si9n" % "sbt-assembly" % "2.1.5")`,
+				"project/build.properties": "sbt.version=1.9.7\n",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsScala,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Version:  "2.13.12",
+						Dependencies: map[string]string{
+							"cats-core": "2.10.0",
+							"scalatest": "3.2.17",
+						},
+					},
+				},
+				{
+					Key:       labels.ArtifactSbtAssembly,
+					LabelData: labels.LabelData{BasePath: "."},
+				},
+			},
+		},
+		{
+			name: "sbt project without plugins",
+			files: map[string]string{
+				"service/build.sbt": `scalaVersion := "3.3.1"`,
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.DepsScala,
+					LabelData: labels.LabelData{
+						BasePath:     "service",
+						Version:      "3.3.1",
+						Dependencies: map[string]string{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
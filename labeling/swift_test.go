@@ -0,0 +1,94 @@
+package labeling
+
+import (
+	"testing"
+
+	"github.com/CircleCI-Public/circleci-config/labeling/codebase"
+	"github.com/CircleCI-Public/circleci-config/labeling/labels"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCodebase_ApplySwiftRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedLabels []labels.Label
+	}{
+		{
+			name: "swift package",
+			files: map[string]string{
+				"Package.swift":    "// swift-tools-version:5.9\nimport PackageDescription\n",
+				"Package.resolved": "{}",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key:       labels.DepsSwift,
+					LabelData: labels.LabelData{BasePath: ".", Version: "5.9", HasLockFile: true},
+				},
+			},
+		},
+		{
+			name: "app with a workspace, pods and fastlane",
+			files: map[string]string{
+				"ios/Shop.xcodeproj/project.pbxproj":                              "",
+				"ios/Shop.xcodeproj/project.xcworkspace/contents.xcworkspacedata": "",
+				"ios/Shop.xcworkspace/contents.xcworkspacedata":                   "",
+				"ios/Pods/Pods.xcodeproj/project.pbxproj":                         "",
+				"ios/Podfile":           "",
+				"ios/Podfile.lock":      "",
+				"ios/fastlane/Fastfile": "",
+				"ios/.xcode-version":    "15.0.1\n",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.ToolXcode,
+					LabelData: labels.LabelData{
+						BasePath: "ios",
+						Version:  "15.0.1",
+						Tasks:    map[string]string{"workspace": "Shop.xcworkspace", "scheme": "Shop"},
+					},
+				},
+				{
+					Key:       labels.PackageManagerCocoapods,
+					LabelData: labels.LabelData{BasePath: "ios", HasLockFile: true},
+				},
+				{
+					Key:       labels.ToolFastlane,
+					LabelData: labels.LabelData{BasePath: "ios"},
+				},
+			},
+		},
+		{
+			name: "xcode project without a workspace",
+			files: map[string]string{
+				"Tool.xcodeproj/project.pbxproj":                              "",
+				"Tool.xcodeproj/project.xcworkspace/contents.xcworkspacedata": "",
+			},
+			expectedLabels: []labels.Label{
+				{
+					Key: labels.ToolXcode,
+					LabelData: labels.LabelData{
+						BasePath: ".",
+						Tasks:    map[string]string{"project": "Tool.xcodeproj", "scheme": "Tool"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codebase.MapCodebase(tt.files)
+			expected := make(labels.LabelSet)
+			for _, label := range tt.expectedLabels {
+				// all should be Valid
+				label.Valid = true
+				expected[label.Key] = label
+			}
+			got := withoutEvidence(ApplyAllRules(c))
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("ApplyAllRules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}